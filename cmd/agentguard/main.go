@@ -6,17 +6,41 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/alereyleyva/agent-guard/internal/approval"
 	"github.com/alereyleyva/agent-guard/internal/audit"
 	"github.com/alereyleyva/agent-guard/internal/config"
 	"github.com/alereyleyva/agent-guard/internal/gateway"
+	"github.com/alereyleyva/agent-guard/internal/pinning"
 	"github.com/alereyleyva/agent-guard/internal/policy"
 	"github.com/alereyleyva/agent-guard/internal/provider"
 )
 
 func main() {
-	configPath := flag.String("config", "config.yaml", "path to configuration file")
-	flag.Parse()
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		serve(args)
+	case "tools":
+		os.Exit(runTools(args))
+	case "policy":
+		os.Exit(runPolicy(args))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q (want serve, tools or policy)\n", command)
+		os.Exit(2)
+	}
+}
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := flags.String("config", "config.yaml", "path to configuration file")
+	_ = flags.Parse(args)
 
 	if envPath := os.Getenv("AGENTGUARD_CONFIG"); envPath != "" {
 		*configPath = envPath
@@ -35,12 +59,59 @@ func main() {
 	policyEngine := policy.NewEngine(cfg.Policy)
 
 	logger := audit.NewStdoutLogger()
+	approvals := approval.NewStore(cfg.Approvals.Timeout)
 
-	flow := gateway.NewFlow(prov, policyEngine, logger)
-	handler := gateway.NewHandler(flow)
+	flowOpts := []gateway.FlowOption{gateway.WithApprovals(approvals), gateway.WithLimits(cfg.Limits)}
+	if len(cfg.ModelAliases) > 0 {
+		flowOpts = append(flowOpts, gateway.WithModelAliases(cfg.ModelAliases))
+	}
+	if cfg.Policy.PII.Action != "" {
+		piiGuard, err := gateway.NewPIIGuard(cfg.Policy.PII)
+		if err != nil {
+			log.Fatalf("failed to initialize pii detection: %v", err)
+		}
+		flowOpts = append(flowOpts, gateway.WithPII(piiGuard))
+	}
+	if cfg.Policy.Secrets.Action != "" {
+		secretGuard, err := gateway.NewSecretGuard(cfg.Policy.Secrets)
+		if err != nil {
+			log.Fatalf("failed to initialize secret detection: %v", err)
+		}
+		flowOpts = append(flowOpts, gateway.WithSecrets(secretGuard))
+	}
+	if cfg.Policy.Injection.Enabled() {
+		injectionGuard, err := gateway.NewInjectionGuard(cfg.Policy.Injection)
+		if err != nil {
+			log.Fatalf("failed to initialize injection screening: %v", err)
+		}
+		flowOpts = append(flowOpts, gateway.WithInjection(injectionGuard))
+	}
+	if cfg.Policy.ToolPoisoning.Action != "" {
+		flowOpts = append(flowOpts, gateway.WithToolPoisoning(gateway.NewPoisoningGuard(cfg.Policy.ToolPoisoning)))
+	}
+
+	pins := pinning.NewStore()
+	if cfg.Policy.ToolPinning.File != "" {
+		pins, err = pinning.LoadFile(cfg.Policy.ToolPinning.File)
+		if err != nil {
+			log.Fatalf("failed to load tool pins: %v", err)
+		}
+	}
+	if cfg.Policy.ToolPinning.OnDrift != "" {
+		if cfg.Policy.ToolPinning.File == "" && cfg.Policy.ToolPinning.PinsNew() {
+			log.Printf("warning: tool pins are only learned per caller identity, which clients choose; set policy.tool_pinning.file to pin definitions for every caller")
+		}
+		flowOpts = append(flowOpts, gateway.WithToolPinning(pins, cfg.Policy.ToolPinning))
+	}
+
+	flow := gateway.NewFlow(prov, policyEngine, logger, flowOpts...)
+	handler := gateway.NewHandler(flow, gateway.WithMaxBodyBytes(cfg.Limits.MaxBodyBytes))
 
 	mux := http.NewServeMux()
 	mux.Handle("/v1/chat/completions", handler)
+	if cfg.Admin.Token != "" {
+		mux.Handle("/admin/", gateway.NewAdminHandler(cfg.Admin.Token, approvals, gateway.WithAdminToolCatalog(pins)))
+	}
 
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/policytest"
+	"github.com/alereyleyva/agent-guard/internal/replay"
+)
+
+const policyUsage = `usage:
+  agentguard policy test -fixtures FILE [-config FILE] [-v]
+  agentguard policy replay [-config FILE] [-v] [-strict] AUDIT_LOG...`
+
+// runPolicy exits 0 on success, 1 when a fixture fails (or, for a strict
+// replay, when any decision changes) and 2 on usage or loading errors.
+func runPolicy(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, policyUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "test":
+		return runPolicyTest(args[1:])
+	case "replay":
+		return runPolicyReplay(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, policyUsage)
+		return 2
+	}
+}
+
+func runPolicyTest(args []string) int {
+	flags := flag.NewFlagSet("policy test", flag.ContinueOnError)
+	configPath := flags.String("config", envOr("AGENTGUARD_CONFIG", "config.yaml"), "path to configuration file")
+	fixtures := flags.String("fixtures", "", "path to the YAML fixtures file")
+	verbose := flags.Bool("v", false, "also list passing cases")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *fixtures == "" {
+		fmt.Fprintln(os.Stderr, policyUsage)
+		return 2
+	}
+
+	cfg, err := config.LoadPolicy(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loading config: %v\n", err)
+		return 2
+	}
+	suite, err := policytest.LoadSuite(*fixtures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loading fixtures: %v\n", err)
+		return 2
+	}
+
+	if failed := policytest.Report(os.Stdout, policytest.Run(*cfg, suite), *verbose); failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func runPolicyReplay(args []string) int {
+	flags := flag.NewFlagSet("policy replay", flag.ContinueOnError)
+	configPath := flags.String("config", envOr("AGENTGUARD_CONFIG", "config.yaml"), "path to the candidate configuration file")
+	verbose := flags.Bool("v", false, "also list unchanged decisions")
+	strict := flags.Bool("strict", false, "exit 1 when any decision changes")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, policyUsage)
+		return 2
+	}
+
+	cfg, err := config.LoadPolicy(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loading config: %v\n", err)
+		return 2
+	}
+
+	var events []audit.Event
+	for _, path := range flags.Args() {
+		fileEvents, err := readAuditLog(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "reading %s: %v\n", path, err)
+			return 2
+		}
+		events = append(events, fileEvents...)
+	}
+
+	evaluations, skipped := replay.Rebuild(events)
+	report := replay.Report{Changes: replay.Run(*cfg, evaluations), Skipped: skipped}
+	report.Write(os.Stdout, *verbose)
+
+	if *strict && report.Counts()[replay.ChangeUnchanged] != len(report.Changes) {
+		return 1
+	}
+	return 0
+}
+
+func readAuditLog(path string) ([]audit.Event, error) {
+	if path == "-" {
+		return replay.ReadEvents(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return replay.ReadEvents(f)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alereyleyva/agent-guard/internal/pinning"
+)
+
+func runTools(args []string) int {
+	if len(args) == 0 || args[0] != "export" {
+		fmt.Fprintln(os.Stderr, "usage: agentguard tools export [-url URL] [-token TOKEN] [-output FILE] [-format yaml|json]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("tools export", flag.ContinueOnError)
+	url := flags.String("url", envOr("AGENTGUARD_ADMIN_URL", "http://localhost:8080"), "base URL of a running gateway")
+	token := flags.String("token", os.Getenv("AGENTGUARD_ADMIN_TOKEN"), "admin API token")
+	output := flags.String("output", "", "write the catalog to this file instead of stdout")
+	format := flags.String("format", "yaml", "output format: yaml or json")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	catalog, err := fetchToolCatalog(*url, *token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "exporting tool catalog: %v\n", err)
+		return 1
+	}
+
+	var data []byte
+	switch *format {
+	case "yaml":
+		data, err = yaml.Marshal(catalog)
+	case "json":
+		data, err = json.MarshalIndent(catalog, "", "  ")
+		data = append(data, '\n')
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported format: %s\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "encoding tool catalog: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		_, _ = os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "writing tool catalog: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func fetchToolCatalog(baseURL, token string) (pinning.PinFile, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(baseURL, "/")+"/admin/tools", nil)
+	if err != nil {
+		return pinning.PinFile{}, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return pinning.PinFile{}, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return pinning.PinFile{}, fmt.Errorf("admin API returned %s", resp.Status)
+	}
+
+	var catalog pinning.PinFile
+	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
+		return pinning.PinFile{}, fmt.Errorf("decoding catalog: %w", err)
+	}
+	return catalog, nil
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,175 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultTimeout = 5 * time.Minute
+
+// maxApproved bounds the approved tool calls remembered for verifying
+// conversation history; the oldest are forgotten first.
+const maxApproved = 10000
+
+const (
+	OutcomeApproved  = "approved"
+	OutcomeRejected  = "rejected"
+	OutcomeExpired   = "expired"
+	OutcomeCancelled = "cancelled"
+)
+
+var ErrNotFound = errors.New("approval request not found")
+
+type Request struct {
+	ID         string    `json:"id"`
+	TraceID    string    `json:"trace_id"`
+	ToolCallID string    `json:"tool_call_id,omitempty"`
+	ToolName   string    `json:"tool_name"`
+	Arguments  string    `json:"arguments"`
+	RuleID     string    `json:"rule_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type Resolution struct {
+	Outcome string `json:"outcome"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+func (r Resolution) Approved() bool {
+	return r.Outcome == OutcomeApproved
+}
+
+type entry struct {
+	request  Request
+	result   chan Resolution
+	resolved bool
+}
+
+type Store struct {
+	mu       sync.Mutex
+	timeout  time.Duration
+	pending  map[string]*entry
+	approved map[string]Request
+	order    []string
+}
+
+func NewStore(timeout time.Duration) *Store {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Store{
+		timeout:  timeout,
+		pending:  make(map[string]*entry),
+		approved: make(map[string]Request),
+	}
+}
+
+func (s *Store) Submit(req Request) Request {
+	now := time.Now().UTC()
+	req.ID = uuid.New().String()
+	req.CreatedAt = now
+	req.ExpiresAt = now.Add(s.timeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[req.ID] = &entry{request: req, result: make(chan Resolution, 1)}
+	return req
+}
+
+// Wait blocks until the request is resolved, its deadline passes or ctx is
+// done. The request is forgotten once Wait returns.
+func (s *Store) Wait(ctx context.Context, id string) Resolution {
+	s.mu.Lock()
+	e, ok := s.pending[id]
+	s.mu.Unlock()
+	if !ok {
+		return Resolution{Outcome: OutcomeExpired, Reason: "approval request not found"}
+	}
+	defer s.forget(id)
+
+	timer := time.NewTimer(time.Until(e.request.ExpiresAt))
+	defer timer.Stop()
+
+	var res Resolution
+	select {
+	case res = <-e.result:
+	case <-timer.C:
+		_ = s.Resolve(id, Resolution{Outcome: OutcomeExpired, Reason: "approval timed out"})
+		// Whichever resolution landed first wins.
+		res = <-e.result
+	case <-ctx.Done():
+		_ = s.Resolve(id, Resolution{Outcome: OutcomeCancelled, Reason: "request cancelled"})
+		res = <-e.result
+	}
+	if res.Approved() {
+		s.remember(e.request)
+	}
+	return res
+}
+
+// Approved returns the approved request for a tool call ID, so a call the
+// client sends back as conversation history can be matched with the approval
+// it was given.
+func (s *Store) Approved(toolCallID string) (Request, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req, ok := s.approved[toolCallID]
+	return req, ok
+}
+
+func (s *Store) remember(req Request) {
+	if req.ToolCallID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.approved[req.ToolCallID]; !ok {
+		s.order = append(s.order, req.ToolCallID)
+	}
+	s.approved[req.ToolCallID] = req
+	for len(s.order) > maxApproved {
+		delete(s.approved, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *Store) Resolve(id string, res Resolution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.pending[id]
+	if !ok || e.resolved {
+		return ErrNotFound
+	}
+	e.resolved = true
+	e.result <- res
+	return nil
+}
+
+func (s *Store) List() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]Request, 0, len(s.pending))
+	for _, e := range s.pending {
+		if !e.resolved {
+			requests = append(requests, e.request)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+	return requests
+}
+
+func (s *Store) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, id)
+}
@@ -0,0 +1,62 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore_ResolveApproved(t *testing.T) {
+	store := NewStore(time.Minute)
+	req := store.Submit(Request{TraceID: "trace-1", ToolCallID: "call-1", ToolName: "deploy", Arguments: `{"env":"prod"}`})
+
+	pending := store.List()
+	if len(pending) != 1 || pending[0].ID != req.ID || pending[0].ToolName != "deploy" {
+		t.Fatalf("List() = %#v, want the submitted request", pending)
+	}
+
+	go func() {
+		_ = store.Resolve(req.ID, Resolution{Outcome: OutcomeApproved})
+	}()
+
+	res := store.Wait(context.Background(), req.ID)
+	if !res.Approved() {
+		t.Errorf("Wait() = %#v, want approved", res)
+	}
+	if len(store.List()) != 0 {
+		t.Errorf("List() should be empty after resolution")
+	}
+	if approved, ok := store.Approved("call-1"); !ok || approved.Arguments != `{"env":"prod"}` {
+		t.Errorf("Approved(call-1) = %#v, %v, want the approved request", approved, ok)
+	}
+}
+
+func TestStore_Timeout(t *testing.T) {
+	store := NewStore(20 * time.Millisecond)
+	req := store.Submit(Request{ToolName: "deploy"})
+
+	res := store.Wait(context.Background(), req.ID)
+	if res.Outcome != OutcomeExpired {
+		t.Errorf("Wait() outcome = %q, want %q", res.Outcome, OutcomeExpired)
+	}
+	if _, ok := store.Approved(""); ok {
+		t.Errorf("Approved() should not remember an expired request")
+	}
+	if err := store.Resolve(req.ID, Resolution{Outcome: OutcomeApproved}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve() after timeout error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_Cancelled(t *testing.T) {
+	store := NewStore(time.Minute)
+	req := store.Submit(Request{ToolName: "deploy"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res := store.Wait(ctx, req.ID)
+	if res.Outcome != OutcomeCancelled {
+		t.Errorf("Wait() outcome = %q, want %q", res.Outcome, OutcomeCancelled)
+	}
+}
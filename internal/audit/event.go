@@ -7,20 +7,41 @@ const (
 	EventTypeLLMResponse    = "llm_response"
 	EventTypeToolProposal   = "tool_proposal"
 	EventTypePolicyDecision = "policy_decision"
+	EventTypeToolHistory    = "tool_history"
+	EventTypeInjectionScore = "injection_score"
+	EventTypeToolPin        = "tool_pin"
+
+	EventTypeApprovalRequested = "approval_requested"
+	EventTypeApprovalGranted   = "approval_granted"
+	EventTypeApprovalRejected  = "approval_rejected"
+	EventTypeApprovalExpired   = "approval_expired"
 )
 
 type Event struct {
-	TraceID   string `json:"trace_id"`
-	Timestamp string `json:"timestamp"`
-	EventType string `json:"event_type"`
-	Provider  string `json:"provider,omitempty"`
-	Model     string `json:"model,omitempty"`
-	Decision  string `json:"decision,omitempty"`
-	RuleID    string `json:"rule_id,omitempty"`
-	Reason    string `json:"reason,omitempty"`
-	ToolName  string `json:"tool_name,omitempty"`
-	Hash      string `json:"hash,omitempty"`
-	Stream    bool   `json:"stream,omitempty"`
+	TraceID        string         `json:"trace_id"`
+	Timestamp      string         `json:"timestamp"`
+	EventType      string         `json:"event_type"`
+	Provider       string         `json:"provider,omitempty"`
+	Model          string         `json:"model,omitempty"`
+	RequestedModel string         `json:"requested_model,omitempty"`
+	Caller         string         `json:"caller,omitempty"`
+	Decision       string         `json:"decision,omitempty"`
+	RuleID         string         `json:"rule_id,omitempty"`
+	Reason         string         `json:"reason,omitempty"`
+	ToolName       string         `json:"tool_name,omitempty"`
+	ApprovalID     string         `json:"approval_id,omitempty"`
+	ToolCallID     string         `json:"tool_call_id,omitempty"`
+	Tags           []string       `json:"tags,omitempty"`
+	MessageIndex   *int           `json:"message_index,omitempty"`
+	Constraint     string         `json:"constraint,omitempty"`
+	Shadow         bool           `json:"shadow,omitempty"`
+	Detections     map[string]int `json:"detections,omitempty"`
+	Score          *float64       `json:"score,omitempty"`
+	Hash           string         `json:"hash,omitempty"`
+	ArgumentsHash  string         `json:"arguments_hash,omitempty"`
+	RewrittenHash  string         `json:"rewritten_arguments_hash,omitempty"`
+	Stream         bool           `json:"stream,omitempty"`
+	History        []string       `json:"history,omitempty"`
 }
 
 func NewEvent(traceID, eventType string) Event {
@@ -41,6 +62,18 @@ func (e Event) WithModel(model string) Event {
 	return e
 }
 
+// WithRequestedModel records the alias the client asked for when the model
+// was resolved from one.
+func (e Event) WithRequestedModel(model string) Event {
+	e.RequestedModel = model
+	return e
+}
+
+func (e Event) WithCaller(caller string) Event {
+	e.Caller = caller
+	return e
+}
+
 func (e Event) WithDecision(action, ruleID, reason string) Event {
 	e.Decision = action
 	e.RuleID = ruleID
@@ -53,6 +86,59 @@ func (e Event) WithToolName(toolName string) Event {
 	return e
 }
 
+func (e Event) WithTags(tags []string) Event {
+	e.Tags = tags
+	return e
+}
+
+func (e Event) WithToolCallID(toolCallID string) Event {
+	e.ToolCallID = toolCallID
+	return e
+}
+
+func (e Event) WithMessageIndex(index int) Event {
+	e.MessageIndex = &index
+	return e
+}
+
+func (e Event) WithApprovalID(approvalID string) Event {
+	e.ApprovalID = approvalID
+	return e
+}
+
+func (e Event) WithConstraint(constraint string) Event {
+	e.Constraint = constraint
+	return e
+}
+
+func (e Event) WithDetections(detections map[string]int) Event {
+	e.Detections = detections
+	return e
+}
+
+func (e Event) WithScore(score float64) Event {
+	e.Score = &score
+	return e
+}
+
+func (e Event) WithArgumentHashes(before, after string) Event {
+	e.ArgumentsHash = before
+	e.RewrittenHash = after
+	return e
+}
+
+// WithHistory records the tool history a tool call decision was evaluated
+// against, so the decision can be replayed without the session state.
+func (e Event) WithHistory(history []string) Event {
+	e.History = history
+	return e
+}
+
+func (e Event) WithShadow(shadow bool) Event {
+	e.Shadow = shadow
+	return e
+}
+
 func (e Event) WithHash(hash string) Event {
 	e.Hash = hash
 	return e
@@ -3,15 +3,41 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Listen   string         `yaml:"listen"`
-	Provider ProviderConfig `yaml:"provider"`
-	Policy   PolicyConfig   `yaml:"policy"`
+	Listen       string            `yaml:"listen"`
+	Provider     ProviderConfig    `yaml:"provider"`
+	ModelAliases map[string]string `yaml:"model_aliases"`
+	Policy       PolicyConfig      `yaml:"policy"`
+	Admin        AdminConfig       `yaml:"admin"`
+	Approvals    ApprovalsConfig   `yaml:"approvals"`
+	Limits       LimitsConfig      `yaml:"limits"`
+}
+
+type AdminConfig struct {
+	Token string `yaml:"token"`
+}
+
+type ApprovalsConfig struct {
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// LimitsConfig caps the shape of a single request. Zero disables a cap.
+type LimitsConfig struct {
+	MaxBodyBytes    int64 `yaml:"max_body_bytes"`
+	MaxMessages     int   `yaml:"max_messages"`
+	MaxMessageChars int   `yaml:"max_message_chars"`
+	MaxTools        int   `yaml:"max_tools"`
+	MaxSchemaDepth  int   `yaml:"max_schema_depth"`
+	// MaxPromptChars bounds the combined message content and tool call
+	// arguments of the request.
+	MaxPromptChars int `yaml:"max_prompt_chars"`
 }
 
 type ProviderConfig struct {
@@ -38,8 +64,234 @@ type BedrockConfig struct {
 }
 
 type PolicyConfig struct {
-	Models ModelPolicy `yaml:"models"`
-	Tools  ToolPolicy  `yaml:"tools"`
+	DryRun         bool             `yaml:"dry_run"`
+	IdentityHeader string           `yaml:"identity_header"`
+	SessionHeader  string           `yaml:"session_header"`
+	SessionTTL     time.Duration    `yaml:"session_ttl"`
+	Rules          []PolicyRule     `yaml:"rules"`
+	Models         ModelPolicy      `yaml:"models"`
+	Tools          ToolPolicy       `yaml:"tools"`
+	ToolCatalog    ToolCatalog      `yaml:"tool_catalog"`
+	ModelParams    []ModelParamRule `yaml:"model_params"`
+	RequestFields  RequestFields    `yaml:"request_fields"`
+	Images         ImagePolicy      `yaml:"images"`
+	Content        []ContentRule    `yaml:"content"`
+	PII            PIIConfig        `yaml:"pii"`
+	Secrets        SecretsConfig    `yaml:"secrets"`
+	Injection      InjectionConfig  `yaml:"injection"`
+	ToolPoisoning  PoisoningConfig  `yaml:"tool_poisoning"`
+	ToolPinning    PinningConfig    `yaml:"tool_pinning"`
+}
+
+type PolicyRule struct {
+	ID       string          `yaml:"id"`
+	Priority int             `yaml:"priority"`
+	Mode     string          `yaml:"mode"`
+	Action   string          `yaml:"action"`
+	Reason   string          `yaml:"reason"`
+	Match    RuleMatch       `yaml:"match"`
+	Patches  []ArgumentPatch `yaml:"patches"`
+}
+
+// ArgumentPatch is one declarative edit applied by a rewrite rule to the
+// JSON arguments of a tool call. Field is a dot path; "*" fans out over
+// arrays and objects.
+type ArgumentPatch struct {
+	Op     string      `yaml:"op"`
+	Field  string      `yaml:"field"`
+	Value  interface{} `yaml:"value"`
+	Values []string    `yaml:"values"`
+	Min    *float64    `yaml:"min"`
+	Max    *float64    `yaml:"max"`
+}
+
+const (
+	PatchOpSet    = "set"
+	PatchOpRemove = "remove"
+	PatchOpClamp  = "clamp"
+	PatchOpStrip  = "strip"
+)
+
+type RuleMatch struct {
+	Models       []string          `yaml:"models"`
+	Tools        []string          `yaml:"tools"`
+	Tags         []string          `yaml:"tags"`
+	Providers    []string          `yaml:"providers"`
+	Callers      []string          `yaml:"callers"`
+	Headers      map[string]string `yaml:"headers"`
+	MessageCount *IntRange         `yaml:"message_count"`
+	Stream       *bool             `yaml:"stream"`
+	Time         *TimeWindow       `yaml:"time"`
+	CalledBefore []string          `yaml:"called_before"`
+	PriorCalls   *IntRange         `yaml:"prior_calls"`
+}
+
+// IsToolRule reports whether the rule applies to tool evaluations rather
+// than to the request as a whole.
+func (m RuleMatch) IsToolRule() bool {
+	return len(m.Tools) > 0 || len(m.Tags) > 0
+}
+
+type IntRange struct {
+	Min *int `yaml:"min"`
+	Max *int `yaml:"max"`
+}
+
+type TimeWindow struct {
+	Start    string   `yaml:"start"`
+	End      string   `yaml:"end"`
+	Days     []string `yaml:"days"`
+	Timezone string   `yaml:"timezone"`
+}
+
+const (
+	RuleActionAllow           = "allow"
+	RuleActionDeny            = "deny"
+	RuleActionRequireApproval = "require_approval"
+	RuleActionRewrite         = "rewrite"
+)
+
+const (
+	RuleModeEnforce = "enforce"
+	RuleModeMonitor = "monitor"
+)
+
+type ToolCatalog struct {
+	// DefaultTags are given to tools that no entry matches.
+	DefaultTags []string       `yaml:"default_tags"`
+	Tools       []ToolTagEntry `yaml:"tools"`
+}
+
+type ToolTagEntry struct {
+	Match []string `yaml:"match"`
+	Tags  []string `yaml:"tags"`
+}
+
+// ModelParamRule bounds the sampling parameters of requests to matching
+// models. Clamp pulls out-of-range values back into range; deny rejects
+// the request. A max_tokens ceiling also applies when the request omits
+// max_tokens, and a response_format requirement is always enforced as a
+// deny since it cannot be clamped.
+type ModelParamRule struct {
+	ID             string      `yaml:"id"`
+	Models         []string    `yaml:"models"`
+	Mode           string      `yaml:"mode"`
+	Action         string      `yaml:"action"`
+	Reason         string      `yaml:"reason"`
+	MaxTokens      *int        `yaml:"max_tokens"`
+	Temperature    *FloatRange `yaml:"temperature"`
+	TopP           *FloatRange `yaml:"top_p"`
+	MaxN           *int        `yaml:"max_n"`
+	ResponseFormat []string    `yaml:"response_format"`
+}
+
+type FloatRange struct {
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+}
+
+const (
+	ModelParamActionClamp = "clamp"
+	ModelParamActionDeny  = "deny"
+)
+
+type ContentRule struct {
+	ID              string   `yaml:"id"`
+	Target          string   `yaml:"target"`
+	Roles           []string `yaml:"roles"`
+	Keywords        []string `yaml:"keywords"`
+	Regex           []string `yaml:"regex"`
+	CaseInsensitive bool     `yaml:"case_insensitive"`
+	Action          string   `yaml:"action"`
+	Mask            string   `yaml:"mask"`
+	Reason          string   `yaml:"reason"`
+}
+
+const (
+	ContentTargetRequest  = "request"
+	ContentTargetResponse = "response"
+	ContentTargetBoth     = "both"
+)
+
+const (
+	ContentActionBlock = "block"
+	ContentActionFlag  = "flag"
+	ContentActionMask  = "mask"
+)
+
+type PIIConfig struct {
+	Action     string   `yaml:"action"`
+	Detectors  []string `yaml:"detectors"`
+	Detokenize bool     `yaml:"detokenize"`
+	Key        string   `yaml:"key"`
+}
+
+const (
+	PIIActionBlock   = "block"
+	PIIActionRedact  = "redact"
+	PIIActionMonitor = "monitor"
+)
+
+type SecretsConfig struct {
+	Action           string   `yaml:"action"`
+	Detectors        []string `yaml:"detectors"`
+	EntropyThreshold float64  `yaml:"entropy_threshold"`
+	EntropyMinLength int      `yaml:"entropy_min_length"`
+}
+
+const (
+	SecretsActionBlock   = "block"
+	SecretsActionMask    = "mask"
+	SecretsActionMonitor = "monitor"
+)
+
+type InjectionConfig struct {
+	// Thresholds are scores in [0, 1]; zero disables that action. The
+	// highest threshold reached wins.
+	Annotate   float64         `yaml:"annotate"`
+	Quarantine float64         `yaml:"quarantine"`
+	Block      float64         `yaml:"block"`
+	Rules      []InjectionRule `yaml:"rules"`
+	Disable    []string        `yaml:"disable"`
+}
+
+type InjectionRule struct {
+	ID     string  `yaml:"id"`
+	Regex  string  `yaml:"regex"`
+	Weight float64 `yaml:"weight"`
+}
+
+func (c InjectionConfig) Enabled() bool {
+	return c.Annotate > 0 || c.Quarantine > 0 || c.Block > 0
+}
+
+type PoisoningConfig struct {
+	Action               string `yaml:"action"`
+	MaxDescriptionLength int    `yaml:"max_description_length"`
+}
+
+const (
+	PoisoningActionBlock   = "block"
+	PoisoningActionStrip   = "strip"
+	PoisoningActionMonitor = "monitor"
+)
+
+type PinningConfig struct {
+	OnDrift string `yaml:"on_drift"`
+	File    string `yaml:"file"`
+	// PinNew pins definitions seen for the first time, per caller. Defaults
+	// to true.
+	PinNew *bool `yaml:"pin_new"`
+}
+
+const (
+	PinDriftAlert = "alert"
+	PinDriftDeny  = "deny"
+	PinDriftRepin = "repin"
+)
+
+func (c PinningConfig) PinsNew() bool {
+	return c.PinNew == nil || *c.PinNew
 }
 
 type ModelPolicy struct {
@@ -48,19 +300,83 @@ type ModelPolicy struct {
 }
 
 type ToolPolicy struct {
-	Allow []string `yaml:"allow"`
-	Deny  []string `yaml:"deny"`
+	Allow       []string         `yaml:"allow"`
+	Deny        []string         `yaml:"deny"`
+	Enforcement string           `yaml:"enforcement"`
+	Declared    string           `yaml:"declared"`
+	History     string           `yaml:"history"`
+	Validation  string           `yaml:"validation"`
+	Constraints []ToolConstraint `yaml:"constraints"`
+}
+
+type ToolConstraint struct {
+	ID         string   `yaml:"id"`
+	Tool       string   `yaml:"tool"`
+	Mode       string   `yaml:"mode"`
+	Field      string   `yaml:"field"`
+	Regex      string   `yaml:"regex"`
+	Prefix     string   `yaml:"prefix"`
+	PathPrefix string   `yaml:"path_prefix"`
+	Min        *float64 `yaml:"min"`
+	Max        *float64 `yaml:"max"`
+	Enum       []string `yaml:"enum"`
+}
+
+const (
+	ToolEnforcementReject = "reject"
+	ToolEnforcementRemove = "remove"
+	ToolEnforcementRefuse = "refuse"
+)
+
+const (
+	DeclaredToolsReject = "reject"
+	DeclaredToolsRemove = "remove"
+)
+
+// RequestFields controls request fields the gateway does not model.
+// Fields matching Passthrough are forwarded upstream; the rest are
+// rejected or dropped according to Unknown.
+type RequestFields struct {
+	Passthrough []string `yaml:"passthrough"`
+	Unknown     string   `yaml:"unknown"`
 }
 
+// ImagePolicy controls image content parts. Allow and Deny are model
+// patterns; an empty Allow lets every model not denied receive images.
+// MaxBytes caps the decoded size of inline images, zero disables it.
+type ImagePolicy struct {
+	Allow           []string `yaml:"allow"`
+	Deny            []string `yaml:"deny"`
+	MaxBytes        int      `yaml:"max_bytes"`
+	BlockRemoteURLs bool     `yaml:"block_remote_urls"`
+}
+
+// Configured reports whether any image policy is set. Without one, images
+// are passed through unchecked.
+func (p ImagePolicy) Configured() bool {
+	return len(p.Allow) > 0 || len(p.Deny) > 0 || p.MaxBytes > 0 || p.BlockRemoteURLs
+}
+
+const (
+	UnknownFieldsReject = "reject"
+	UnknownFieldsDrop   = "drop"
+)
+
+const (
+	ToolHistoryReject = "reject"
+	ToolHistoryStrip  = "strip"
+)
+
+const (
+	ToolValidationOff         = "off"
+	ToolValidationDeny        = "deny"
+	ToolValidationErrorResult = "error_result"
+)
+
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	cfg, err := read(path)
 	if err != nil {
-		return nil, fmt.Errorf("reading config file: %w", err)
-	}
-
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parsing config file: %w", err)
+		return nil, err
 	}
 
 	cfg.Provider.APIKey = resolveEnvVar(cfg.Provider.APIKey)
@@ -74,11 +390,40 @@ func Load(path string) (*Config, error) {
 	cfg.Provider.Bedrock.SecretAccessKey = resolveEnvVar(cfg.Provider.Bedrock.SecretAccessKey)
 	cfg.Provider.Bedrock.SessionToken = resolveEnvVar(cfg.Provider.Bedrock.SessionToken)
 	cfg.Provider.Bedrock.Endpoint = resolveEnvVar(cfg.Provider.Bedrock.Endpoint)
+	cfg.Admin.Token = resolveEnvVar(cfg.Admin.Token)
+	cfg.Policy.PII.Key = resolveEnvVar(cfg.Policy.PII.Key)
 
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("validating config: %w", err)
 	}
 
+	return cfg, nil
+}
+
+// LoadPolicy reads a config for offline policy evaluation (policy test and
+// replay). Only policy and model_aliases are validated, so the listen
+// address, provider credentials and admin token may be missing.
+func LoadPolicy(path string) (*Config, error) {
+	cfg, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validatePolicy(); err != nil {
+		return nil, fmt.Errorf("validating config: %w", err)
+	}
+	return cfg, nil
+}
+
+func read(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
 	return &cfg, nil
 }
 
@@ -120,5 +465,357 @@ func (c *Config) validate() error {
 	default:
 		return fmt.Errorf("unsupported provider type: %s", c.Provider.Type)
 	}
+
+	if c.Admin.Token == "" && c.Policy.requiresApproval() {
+		return fmt.Errorf("admin token is required when a rule uses require_approval")
+	}
+	if c.Approvals.Timeout < 0 {
+		return fmt.Errorf("approvals timeout must not be negative")
+	}
+	if err := c.Limits.validate(); err != nil {
+		return err
+	}
+	return c.validatePolicy()
+}
+
+func (c *Config) validatePolicy() error {
+	switch c.Policy.Tools.Enforcement {
+	case "", ToolEnforcementReject, ToolEnforcementRemove, ToolEnforcementRefuse:
+	default:
+		return fmt.Errorf("unsupported tool enforcement mode: %s", c.Policy.Tools.Enforcement)
+	}
+	switch c.Policy.Tools.Declared {
+	case "", DeclaredToolsReject, DeclaredToolsRemove:
+	default:
+		return fmt.Errorf("unsupported declared tools mode: %s", c.Policy.Tools.Declared)
+	}
+	switch c.Policy.Tools.History {
+	case "", ToolHistoryReject, ToolHistoryStrip:
+	default:
+		return fmt.Errorf("unsupported tool history mode: %s", c.Policy.Tools.History)
+	}
+	switch c.Policy.Tools.Validation {
+	case "", ToolValidationOff, ToolValidationDeny, ToolValidationErrorResult:
+	default:
+		return fmt.Errorf("unsupported tool validation mode: %s", c.Policy.Tools.Validation)
+	}
+	switch c.Policy.RequestFields.Unknown {
+	case "", UnknownFieldsReject, UnknownFieldsDrop:
+	default:
+		return fmt.Errorf("unsupported unknown request fields mode: %s", c.Policy.RequestFields.Unknown)
+	}
+	if err := c.Policy.validateRules(); err != nil {
+		return err
+	}
+	for i, entry := range c.Policy.ToolCatalog.Tools {
+		if len(entry.Match) == 0 || len(entry.Tags) == 0 {
+			return fmt.Errorf("tool catalog entry %d: match and tags are required", i)
+		}
+	}
+	if c.Policy.Images.MaxBytes < 0 {
+		return fmt.Errorf("images max_bytes must not be negative")
+	}
+	if err := c.Policy.validateModelParams(); err != nil {
+		return err
+	}
+	if err := c.Policy.validateContentRules(); err != nil {
+		return err
+	}
+	switch c.Policy.PII.Action {
+	case "", PIIActionBlock, PIIActionRedact, PIIActionMonitor:
+	default:
+		return fmt.Errorf("unsupported pii action: %s", c.Policy.PII.Action)
+	}
+	switch c.Policy.Secrets.Action {
+	case "", SecretsActionBlock, SecretsActionMask, SecretsActionMonitor:
+	default:
+		return fmt.Errorf("unsupported secrets action: %s", c.Policy.Secrets.Action)
+	}
+	if c.Policy.Secrets.EntropyThreshold < 0 || c.Policy.Secrets.EntropyMinLength < 0 {
+		return fmt.Errorf("secrets entropy settings must not be negative")
+	}
+	if err := c.Policy.Injection.validate(); err != nil {
+		return err
+	}
+	switch c.Policy.ToolPoisoning.Action {
+	case "", PoisoningActionBlock, PoisoningActionStrip, PoisoningActionMonitor:
+	default:
+		return fmt.Errorf("unsupported tool poisoning action: %s", c.Policy.ToolPoisoning.Action)
+	}
+	switch c.Policy.ToolPinning.OnDrift {
+	case "", PinDriftAlert, PinDriftDeny, PinDriftRepin:
+	default:
+		return fmt.Errorf("unsupported tool pinning on_drift: %s", c.Policy.ToolPinning.OnDrift)
+	}
+	if c.Policy.ToolPoisoning.MaxDescriptionLength < 0 {
+		return fmt.Errorf("tool poisoning max_description_length must not be negative")
+	}
+	if c.Policy.SessionTTL < 0 {
+		return fmt.Errorf("policy session_ttl must not be negative")
+	}
+	for alias, model := range c.ModelAliases {
+		if model == "" {
+			return fmt.Errorf("model alias %q: target model is required", alias)
+		}
+	}
+	for i, constraint := range c.Policy.Tools.Constraints {
+		if constraint.ID == "" {
+			return fmt.Errorf("tool constraint %d: id is required", i)
+		}
+		if constraint.Tool == "" {
+			return fmt.Errorf("tool constraint %q: tool is required", constraint.ID)
+		}
+		if err := validateRuleMode(constraint.Mode); err != nil {
+			return fmt.Errorf("tool constraint %q: %w", constraint.ID, err)
+		}
+		if constraint.Regex != "" {
+			if _, err := regexp.Compile(constraint.Regex); err != nil {
+				return fmt.Errorf("tool constraint %q: invalid regex: %w", constraint.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p PolicyConfig) validateRules() error {
+	seen := make(map[string]bool, len(p.Rules))
+	for i, rule := range p.Rules {
+		if rule.ID == "" {
+			return fmt.Errorf("policy rule %d: id is required", i)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("policy rule %q: duplicate id", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		switch rule.Action {
+		case RuleActionAllow, RuleActionDeny:
+		case RuleActionRequireApproval:
+			if !rule.Match.IsToolRule() {
+				return fmt.Errorf("policy rule %q: require_approval needs a tools or tags condition", rule.ID)
+			}
+		case RuleActionRewrite:
+			if !rule.Match.IsToolRule() {
+				return fmt.Errorf("policy rule %q: rewrite needs a tools or tags condition", rule.ID)
+			}
+			if len(rule.Patches) == 0 {
+				return fmt.Errorf("policy rule %q: rewrite needs at least one patch", rule.ID)
+			}
+			for i, patch := range rule.Patches {
+				if err := patch.validate(); err != nil {
+					return fmt.Errorf("policy rule %q: patch %d: %w", rule.ID, i, err)
+				}
+			}
+		default:
+			return fmt.Errorf("policy rule %q: unsupported action: %s", rule.ID, rule.Action)
+		}
+		if err := validateRuleMode(rule.Mode); err != nil {
+			return fmt.Errorf("policy rule %q: %w", rule.ID, err)
+		}
+		if (len(rule.Match.CalledBefore) > 0 || rule.Match.PriorCalls != nil) && !rule.Match.IsToolRule() {
+			return fmt.Errorf("policy rule %q: called_before and prior_calls need a tools or tags condition", rule.ID)
+		}
+
+		if window := rule.Match.Time; window != nil {
+			if err := window.Validate(); err != nil {
+				return fmt.Errorf("policy rule %q: %w", rule.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p PolicyConfig) requiresApproval() bool {
+	for _, rule := range p.Rules {
+		if rule.Action == RuleActionRequireApproval {
+			return true
+		}
+	}
+	return false
+}
+
+func (p PolicyConfig) validateModelParams() error {
+	seen := make(map[string]bool, len(p.ModelParams))
+	for i, rule := range p.ModelParams {
+		if rule.ID == "" {
+			return fmt.Errorf("model param rule %d: id is required", i)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("model param rule %q: duplicate id", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		switch rule.Action {
+		case ModelParamActionClamp, ModelParamActionDeny:
+		default:
+			return fmt.Errorf("model param rule %q: unsupported action: %s", rule.ID, rule.Action)
+		}
+		if err := validateRuleMode(rule.Mode); err != nil {
+			return fmt.Errorf("model param rule %q: %w", rule.ID, err)
+		}
+		if rule.MaxTokens != nil && *rule.MaxTokens < 1 {
+			return fmt.Errorf("model param rule %q: max_tokens must be positive", rule.ID)
+		}
+		if rule.MaxN != nil && *rule.MaxN < 1 {
+			return fmt.Errorf("model param rule %q: max_n must be positive", rule.ID)
+		}
+		for name, r := range map[string]*FloatRange{"temperature": rule.Temperature, "top_p": rule.TopP} {
+			if r != nil && r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+				return fmt.Errorf("model param rule %q: %s min is greater than max", rule.ID, name)
+			}
+		}
+	}
+	return nil
+}
+
+func (p PolicyConfig) validateContentRules() error {
+	seen := make(map[string]bool, len(p.Content))
+	for i, rule := range p.Content {
+		if rule.ID == "" {
+			return fmt.Errorf("content rule %d: id is required", i)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("content rule %q: duplicate id", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		switch rule.Target {
+		case "", ContentTargetRequest, ContentTargetResponse, ContentTargetBoth:
+		default:
+			return fmt.Errorf("content rule %q: unsupported target: %s", rule.ID, rule.Target)
+		}
+		switch rule.Action {
+		case ContentActionBlock, ContentActionFlag, ContentActionMask:
+		default:
+			return fmt.Errorf("content rule %q: unsupported action: %s", rule.ID, rule.Action)
+		}
+		if len(rule.Keywords) == 0 && len(rule.Regex) == 0 {
+			return fmt.Errorf("content rule %q: keywords or regex is required", rule.ID)
+		}
+		for _, expr := range rule.Regex {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("content rule %q: invalid regex: %w", rule.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c LimitsConfig) validate() error {
+	if c.MaxBodyBytes < 0 {
+		return fmt.Errorf("limits max_body_bytes must not be negative")
+	}
+	for name, limit := range map[string]int{
+		"max_messages":      c.MaxMessages,
+		"max_message_chars": c.MaxMessageChars,
+		"max_tools":         c.MaxTools,
+		"max_schema_depth":  c.MaxSchemaDepth,
+		"max_prompt_chars":  c.MaxPromptChars,
+	} {
+		if limit < 0 {
+			return fmt.Errorf("limits %s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+func (c InjectionConfig) validate() error {
+	for _, threshold := range []float64{c.Annotate, c.Quarantine, c.Block} {
+		if threshold < 0 || threshold > 1 {
+			return fmt.Errorf("injection thresholds must be between 0 and 1")
+		}
+	}
+	for i, rule := range c.Rules {
+		if rule.ID == "" {
+			return fmt.Errorf("injection rule %d: id is required", i)
+		}
+		if rule.Weight <= 0 {
+			return fmt.Errorf("injection rule %q: weight must be positive", rule.ID)
+		}
+		if _, err := regexp.Compile(rule.Regex); err != nil {
+			return fmt.Errorf("injection rule %q: invalid regex: %w", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (p ArgumentPatch) validate() error {
+	if p.Field == "" {
+		return fmt.Errorf("field is required")
+	}
+	switch p.Op {
+	case PatchOpSet:
+		if p.Value == nil {
+			return fmt.Errorf("set needs a value")
+		}
+	case PatchOpRemove:
+	case PatchOpClamp:
+		if p.Min == nil && p.Max == nil {
+			return fmt.Errorf("clamp needs min or max")
+		}
+	case PatchOpStrip:
+		if len(p.Values) == 0 {
+			return fmt.Errorf("strip needs values")
+		}
+	default:
+		return fmt.Errorf("unsupported op: %s", p.Op)
+	}
+	return nil
+}
+
+func validateRuleMode(mode string) error {
+	switch mode {
+	case "", RuleModeEnforce, RuleModeMonitor:
+		return nil
+	default:
+		return fmt.Errorf("unsupported mode: %s", mode)
+	}
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func ParseWeekday(day string) (time.Weekday, bool) {
+	weekday, ok := weekdays[strings.ToLower(day)]
+	return weekday, ok
+}
+
+func ParseClock(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, want HH:MM", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+func (w TimeWindow) Validate() error {
+	if (w.Start == "") != (w.End == "") {
+		return fmt.Errorf("time window needs both start and end")
+	}
+	if w.Start != "" {
+		if _, err := ParseClock(w.Start); err != nil {
+			return err
+		}
+		if _, err := ParseClock(w.End); err != nil {
+			return err
+		}
+	}
+	for _, day := range w.Days {
+		if _, ok := ParseWeekday(day); !ok {
+			return fmt.Errorf("invalid day %q", day)
+		}
+	}
+	if w.Timezone != "" {
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+		}
+	}
 	return nil
 }
@@ -155,3 +155,242 @@ provider:
 		t.Error("Load() should return error when bedrock secret_access_key is missing")
 	}
 }
+
+func TestLoad_InvalidToolEnforcement(t *testing.T) {
+	content := `
+listen: "127.0.0.1:8080"
+provider:
+  type: "openai"
+  base_url: "https://api.openai.com"
+policy:
+  tools:
+    enforcement: "ignore"
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	_, err := Load(configPath)
+	if err == nil {
+		t.Error("Load() should return error for unsupported tool enforcement mode")
+	}
+}
+
+func TestLoad_InvalidUnknownFieldsMode(t *testing.T) {
+	content := `
+listen: "127.0.0.1:8080"
+provider:
+  type: "openai"
+  base_url: "https://api.openai.com"
+policy:
+  request_fields:
+    unknown: "forward"
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	_, err := Load(configPath)
+	if err == nil {
+		t.Error("Load() should return error for unsupported unknown request fields mode")
+	}
+}
+
+func TestLoad_ApprovalRequiresAdminToken(t *testing.T) {
+	content := `
+listen: "127.0.0.1:8080"
+provider:
+  type: "openai"
+  base_url: "https://api.openai.com"
+policy:
+  rules:
+    - id: "approve-deploys"
+      action: "require_approval"
+      match:
+        tools: ["deploy_*"]
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	if _, err := Load(configPath); err == nil {
+		t.Error("Load() should require an admin token when a rule uses require_approval")
+	}
+
+	withToken := content + `
+admin:
+  token: "secret"
+`
+	if err := os.WriteFile(configPath, []byte(withToken), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	if _, err := Load(configPath); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+}
+
+func TestLoad_PolicyRulesValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{"missing id", `
+    - action: "deny"`},
+		{"unknown action", `
+    - id: "r1"
+      action: "block"`},
+		{"duplicate id", `
+    - id: "r1"
+      action: "deny"
+    - id: "r1"
+      action: "allow"`},
+		{"approval without tools", `
+    - id: "r1"
+      action: "require_approval"
+      match:
+        models: ["gpt-4o"]`},
+		{"bad time window", `
+    - id: "r1"
+      action: "deny"
+      match:
+        time:
+          start: "25:00"
+          end: "09:00"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `
+listen: "127.0.0.1:8080"
+provider:
+  type: "openai"
+  base_url: "https://api.openai.com"
+policy:
+  rules:` + tt.rules + "\n"
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write test config: %v", err)
+			}
+
+			if _, err := Load(configPath); err == nil {
+				t.Error("Load() should return error for invalid policy rule")
+			}
+		})
+	}
+}
+
+func TestLoad_ModelParamsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr bool
+	}{
+		{"valid", `
+    - id: "budget"
+      models: ["gpt-4o*"]
+      action: "clamp"
+      max_tokens: 1024
+      temperature:
+        min: 0
+        max: 1`, false},
+		{"unknown action", `
+    - id: "budget"
+      action: "truncate"`, true},
+		{"inverted range", `
+    - id: "budget"
+      action: "deny"
+      top_p:
+        min: 0.9
+        max: 0.1`, true},
+		{"non-positive max_tokens", `
+    - id: "budget"
+      action: "clamp"
+      max_tokens: 0`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `
+listen: "127.0.0.1:8080"
+provider:
+  type: "openai"
+  base_url: "https://api.openai.com"
+policy:
+  model_params:` + tt.rules + "\n"
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write test config: %v", err)
+			}
+
+			_, err := Load(configPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_NegativeLimit(t *testing.T) {
+	content := `
+listen: "127.0.0.1:8080"
+provider:
+  type: "openai"
+  base_url: "https://api.openai.com"
+limits:
+  max_messages: -1
+`
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	if _, err := Load(configPath); err == nil {
+		t.Error("Load() should return error for a negative limit")
+	}
+}
+
+func TestLoadPolicy_SkipsServeChecks(t *testing.T) {
+	content := `
+provider:
+  type: "openrouter"
+  api_key: "env:AGENTGUARD_TEST_UNSET_KEY"
+policy:
+  rules:
+    - id: "approve-deploys"
+      action: "require_approval"
+      match:
+        tools: ["deploy_*"]
+model_aliases:
+  cheap: "gpt-4o-mini"
+`
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	if _, err := Load(configPath); err == nil {
+		t.Error("Load() should apply serve-time checks")
+	}
+	cfg, err := LoadPolicy(configPath)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	if cfg.ModelAliases["cheap"] != "gpt-4o-mini" || len(cfg.Policy.Rules) != 1 {
+		t.Errorf("LoadPolicy() = %+v, want policy and aliases", cfg)
+	}
+
+	invalid := content + `  broken: ""
+`
+	if err := os.WriteFile(configPath, []byte(invalid), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	if _, err := LoadPolicy(configPath); err == nil {
+		t.Error("LoadPolicy() should still validate model_aliases")
+	}
+}
@@ -0,0 +1,116 @@
+package detect
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Finding is a single detector hit. Value holds the matched text and must
+// never be written to audit events.
+type Finding struct {
+	Detector string
+	Start    int
+	End      int
+	Value    string
+}
+
+type Detector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Validate, when set, rejects pattern matches that fail a checksum or
+	// other structural check.
+	Validate func(value string) bool
+}
+
+func (d Detector) Find(text string) []Finding {
+	findings := make([]Finding, 0)
+	for _, loc := range d.Pattern.FindAllStringIndex(text, -1) {
+		value := text[loc[0]:loc[1]]
+		if d.Validate != nil && !d.Validate(value) {
+			continue
+		}
+		findings = append(findings, Finding{Detector: d.Name, Start: loc[0], End: loc[1], Value: value})
+	}
+	return findings
+}
+
+type Scanner struct {
+	detectors []Detector
+}
+
+func NewScanner(detectors ...Detector) *Scanner {
+	return &Scanner{detectors: detectors}
+}
+
+// Scan returns non-overlapping findings ordered by position. When two hits
+// overlap, the one that starts first wins, then the earlier detector.
+func (s *Scanner) Scan(text string) []Finding {
+	type ranked struct {
+		Finding
+		rank int
+	}
+	all := make([]ranked, 0)
+	for rank, detector := range s.detectors {
+		for _, finding := range detector.Find(text) {
+			all = append(all, ranked{Finding: finding, rank: rank})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Start != all[j].Start {
+			return all[i].Start < all[j].Start
+		}
+		return all[i].rank < all[j].rank
+	})
+
+	findings := make([]Finding, 0, len(all))
+	end := 0
+	for _, r := range all {
+		if r.Start < end {
+			continue
+		}
+		findings = append(findings, r.Finding)
+		end = r.End
+	}
+	return findings
+}
+
+func Counts(findings []Finding) map[string]int {
+	if len(findings) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, finding := range findings {
+		counts[finding.Detector]++
+	}
+	return counts
+}
+
+func MergeCounts(dst, src map[string]int) map[string]int {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]int, len(src))
+	}
+	for name, count := range src {
+		dst[name] += count
+	}
+	return dst
+}
+
+// Replace rewrites text, substituting every finding (as returned by Scan)
+// with the result of replace.
+func Replace(text string, findings []Finding, replace func(Finding) string) string {
+	if len(findings) == 0 {
+		return text
+	}
+	out := make([]byte, 0, len(text))
+	last := 0
+	for _, finding := range findings {
+		out = append(out, text[last:finding.Start]...)
+		out = append(out, replace(finding)...)
+		last = finding.End
+	}
+	out = append(out, text[last:]...)
+	return string(out)
+}
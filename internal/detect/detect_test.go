@@ -0,0 +1,26 @@
+package detect
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestScanner_ResolvesOverlaps(t *testing.T) {
+	scanner := NewScanner(
+		Detector{Name: "long", Pattern: regexp.MustCompile(`\d{6}`)},
+		Detector{Name: "short", Pattern: regexp.MustCompile(`\d{3}`)},
+	)
+
+	findings := scanner.Scan("123456 789")
+	if len(findings) != 2 {
+		t.Fatalf("findings = %#v", findings)
+	}
+	if findings[0].Detector != "long" || findings[1].Detector != "short" || findings[1].Value != "789" {
+		t.Errorf("findings = %#v", findings)
+	}
+
+	got := Replace("123456 789", findings, func(f Finding) string { return "[" + f.Detector + "]" })
+	if got != "[long] [short]" {
+		t.Errorf("Replace() = %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+package detect
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	InjectionIgnoreInstructions = "ignore_instructions"
+	InjectionRoleSpoofing       = "role_spoofing"
+	InjectionNewInstructions    = "new_instructions"
+	InjectionHiddenUnicode      = "hidden_unicode"
+	InjectionEncodedPayload     = "encoded_instructions"
+)
+
+type InjectionRule struct {
+	ID     string
+	Weight float64
+	Match  func(text string) bool
+}
+
+func PatternRule(id string, weight float64, pattern *regexp.Regexp) InjectionRule {
+	return InjectionRule{ID: id, Weight: weight, Match: pattern.MatchString}
+}
+
+var (
+	ignoreInstructionsPattern = regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,40}\b(previous|prior|above|earlier|all|any|your)\b.{0,20}\b(instructions?|prompts?|rules|directions|guidelines)\b`)
+	roleSpoofingPattern       = regexp.MustCompile(`(?im)(^\s*(system|assistant|developer)\s*:|<\|im_(start|end)\|>|\[/?INST\]|</?(system|assistant)>|###\s*(system|instruction))`)
+	newInstructionsPattern    = regexp.MustCompile(`(?i)\b(you are now|new instructions|from now on,? you|your new (task|goal|role) is)\b`)
+	base64Pattern             = regexp.MustCompile(`[A-Za-z0-9+/]{24,}={0,2}`)
+)
+
+var textRules = []InjectionRule{
+	PatternRule(InjectionIgnoreInstructions, 0.6, ignoreInstructionsPattern),
+	PatternRule(InjectionRoleSpoofing, 0.5, roleSpoofingPattern),
+	PatternRule(InjectionNewInstructions, 0.4, newInstructionsPattern),
+}
+
+func DefaultInjectionRules() []InjectionRule {
+	rules := append([]InjectionRule{}, textRules...)
+	return append(rules,
+		InjectionRule{ID: InjectionHiddenUnicode, Weight: 0.7, Match: hasHiddenUnicode},
+		InjectionRule{ID: InjectionEncodedPayload, Weight: 0.6, Match: hasEncodedInstructions},
+	)
+}
+
+type InjectionScorer struct {
+	rules []InjectionRule
+}
+
+func NewInjectionScorer(rules ...InjectionRule) *InjectionScorer {
+	return &InjectionScorer{rules: rules}
+}
+
+// Score sums the weights of every matching rule, capped at 1.
+func (s *InjectionScorer) Score(text string) (float64, []string) {
+	score := 0.0
+	matched := make([]string, 0)
+	for _, rule := range s.rules {
+		if rule.Match(text) {
+			score += rule.Weight
+			matched = append(matched, rule.ID)
+		}
+	}
+	if score > 1 {
+		score = 1
+	}
+	return score, matched
+}
+
+// hasHiddenUnicode reports Unicode tag characters, which render as nothing
+// but are read by models, and runs of zero-width or bidi control characters.
+func hasHiddenUnicode(text string) bool {
+	invisible := 0
+	for _, r := range text {
+		switch {
+		case r >= 0xE0000 && r <= 0xE007F:
+			return true
+		case r == 0x200B || r == 0x200C || r == 0x200D || r == 0x2060 || r == 0xFEFF,
+			r >= 0x202A && r <= 0x202E, r >= 0x2066 && r <= 0x2069:
+			invisible++
+			if invisible >= 3 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasEncodedInstructions(text string) bool {
+	for _, blob := range base64Pattern.FindAllString(text, -1) {
+		decoded, err := base64.StdEncoding.DecodeString(blob)
+		if err != nil {
+			decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(blob, "="))
+			if err != nil {
+				continue
+			}
+		}
+		if !isPrintable(decoded) {
+			continue
+		}
+		for _, rule := range textRules {
+			if rule.Match(string(decoded)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package detect
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+const (
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIICreditCard = "credit_card"
+	PIINationalID = "national_id"
+	PIIIBAN       = "iban"
+)
+
+// piiDetectors is ordered so that the more specific formats win when a
+// match overlaps a looser one, e.g. a card number that also looks like a
+// phone number.
+var piiDetectors = []Detector{
+	{
+		Name:     PIICreditCard,
+		Pattern:  regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Validate: validCreditCard,
+	},
+	{
+		Name:     PIIIBAN,
+		Pattern:  regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		Validate: validIBAN,
+	},
+	{
+		Name:     PIINationalID,
+		Pattern:  regexp.MustCompile(`\b(?:\d{3}-\d{2}-\d{4}|\d{8}[A-Za-z]|[XYZxyz]\d{7}[A-Za-z])\b`),
+		Validate: validNationalID,
+	},
+	{
+		Name:    PIIEmail,
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		Name:     PIIPhone,
+		Pattern:  regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?)?\d{2,4}(?:[ .-]?\d{2,4}){2,4}`),
+		Validate: validPhone,
+	},
+}
+
+// PIIDetectors returns the named detectors in precedence order, or all of
+// them when no names are given.
+func PIIDetectors(names ...string) ([]Detector, error) {
+	if len(names) == 0 {
+		return append([]Detector{}, piiDetectors...), nil
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	detectors := make([]Detector, 0, len(names))
+	for _, detector := range piiDetectors {
+		if wanted[detector.Name] {
+			detectors = append(detectors, detector)
+			delete(wanted, detector.Name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("unknown pii detector: %s", name)
+	}
+	return detectors, nil
+}
+
+func digitsOf(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func validCreditCard(value string) bool {
+	digits := digitsOf(value)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	return luhn(digits)
+}
+
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func validIBAN(value string) bool {
+	iban := strings.ReplaceAll(value, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	rearranged := iban[4:] + iban[:4]
+	var numeric strings.Builder
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			numeric.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			fmt.Fprintf(&numeric, "%d", r-'A'+10)
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && n.Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+const dniLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// validNationalID accepts US SSNs and Spanish DNI/NIE numbers, checking
+// the SSN reserved ranges and the DNI/NIE control letter.
+func validNationalID(value string) bool {
+	if strings.Contains(value, "-") {
+		area, group, serial := value[0:3], value[4:6], value[7:11]
+		return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+	}
+
+	upper := strings.ToUpper(value)
+	number := upper[:len(upper)-1]
+	switch number[0] {
+	case 'X':
+		number = "0" + number[1:]
+	case 'Y':
+		number = "1" + number[1:]
+	case 'Z':
+		number = "2" + number[1:]
+	}
+	n, ok := new(big.Int).SetString(number, 10)
+	if !ok {
+		return false
+	}
+	return dniLetters[n.Int64()%23] == upper[len(upper)-1]
+}
+
+var (
+	// dateShaped matches a leading YYYY-MM-DD or DD-MM-YYYY date, which the
+	// phone pattern would otherwise join with the hour of a timestamp.
+	dateShaped = regexp.MustCompile(`^(?:\d{4}[-.](?:0[1-9]|1[0-2])[-.](?:0[1-9]|[12]\d|3[01])|(?:0?[1-9]|[12]\d|3[01])[-.](?:0?[1-9]|1[0-2])[-.]\d{4})\b`)
+	dottedQuad = regexp.MustCompile(`^(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)$`)
+)
+
+func validPhone(value string) bool {
+	digits := digitsOf(value)
+	if len(digits) > 15 {
+		return false
+	}
+	if strings.HasPrefix(value, "+") {
+		return len(digits) >= 9
+	}
+	// Without a country code, require a full national number and some
+	// phone-like formatting so plain IDs and timestamps are not reported.
+	if dateShaped.MatchString(value) || dottedQuad.MatchString(value) {
+		return false
+	}
+	return len(digits) >= 10 && strings.ContainsAny(value, " .-()")
+}
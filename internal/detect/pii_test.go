@@ -0,0 +1,58 @@
+package detect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPIIDetectors(t *testing.T) {
+	detectors, err := PIIDetectors()
+	if err != nil {
+		t.Fatalf("PIIDetectors() error = %v", err)
+	}
+	scanner := NewScanner(detectors...)
+
+	tests := []struct {
+		name string
+		text string
+		want map[string]int
+	}{
+		{"email", "write to jane.doe+test@example.co.uk today", map[string]int{PIIEmail: 1}},
+		{"phone international", "call +34 612 345 678", map[string]int{PIIPhone: 1}},
+		{"phone us", "call (415) 555-0132 now", map[string]int{PIIPhone: 1}},
+		{"plain number is not a phone", "order 123456789012", nil},
+		{"phone dotted", "call 415.555.0132 now", map[string]int{PIIPhone: 1}},
+		{"timestamp is not a phone", "created at 2024-01-15 10:30:00", nil},
+		{"european date is not a phone", "due 15-01-2024 10:30", nil},
+		{"ip address is not a phone", "host 192.168.100.200 is down", nil},
+		{"card passes luhn", "card 4111 1111 1111 1111 exp 12/29", map[string]int{PIICreditCard: 1}},
+		{"card fails luhn", "card 4111 1111 1111 1112", nil},
+		{"ssn", "ssn 123-45-6789", map[string]int{PIINationalID: 1}},
+		{"ssn reserved area", "ssn 666-45-6789", nil},
+		{"dni", "DNI 12345678Z", map[string]int{PIINationalID: 1}},
+		{"dni wrong letter", "DNI 12345678A", nil},
+		{"nie", "NIE X1234567L", map[string]int{PIINationalID: 1}},
+		{"iban", "pay to GB82 WEST 1234 5698 7654 32", map[string]int{PIIIBAN: 1}},
+		{"iban bad checksum", "pay to GB82WEST12345698765433", nil},
+		{"mixed", "a@b.io and 4111111111111111", map[string]int{PIIEmail: 1, PIICreditCard: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Counts(scanner.Scan(tt.text))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Counts(Scan(%q)) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPIIDetectors_Selection(t *testing.T) {
+	detectors, err := PIIDetectors(PIIEmail)
+	if err != nil || len(detectors) != 1 || detectors[0].Name != PIIEmail {
+		t.Errorf("PIIDetectors(email) = %v, %v", detectors, err)
+	}
+	if _, err := PIIDetectors("passport"); err == nil {
+		t.Errorf("PIIDetectors(passport) expected error")
+	}
+}
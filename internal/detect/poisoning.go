@@ -0,0 +1,140 @@
+package detect
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+const (
+	PoisonInjection       = "injection"
+	PoisonHiddenUnicode   = "hidden_unicode"
+	PoisonExcessiveLength = "excessive_length"
+	PoisonToolReference   = "tool_reference"
+)
+
+const (
+	defaultMaxDescriptionLength = 1024
+	maxReferencePatterns        = 1024
+)
+
+var hiddenTagPattern = regexp.MustCompile(`(?i)<\s*/?\s*(important|instructions?|secret|hidden|system)\s*>`)
+
+type ToolFinding struct {
+	Check string
+	// Field is "description" or the JSON path of a parameter description,
+	// e.g. "parameters.properties.path.description".
+	Field string
+}
+
+type ToolInspector struct {
+	MaxDescriptionLength int
+	scorer               *InjectionScorer
+
+	mu         sync.Mutex
+	references map[string]*regexp.Regexp
+}
+
+func NewToolInspector(maxDescriptionLength int) *ToolInspector {
+	if maxDescriptionLength <= 0 {
+		maxDescriptionLength = defaultMaxDescriptionLength
+	}
+	rules := append([]InjectionRule{}, textRules...)
+	rules = append(rules,
+		PatternRule("hidden_tags", 0.5, hiddenTagPattern),
+		InjectionRule{ID: InjectionEncodedPayload, Weight: 0.6, Match: hasEncodedInstructions},
+	)
+	return &ToolInspector{
+		MaxDescriptionLength: maxDescriptionLength,
+		scorer:               NewInjectionScorer(rules...),
+		references:           make(map[string]*regexp.Regexp),
+	}
+}
+
+// Inspect checks a tool's description and every description nested in its
+// parameter schema. otherTools are the names of the other tools declared in
+// the same request.
+func (i *ToolInspector) Inspect(description string, parameters interface{}, otherTools []string) []ToolFinding {
+	texts := map[string]string{"description": description}
+	collectDescriptions(parameters, "parameters", texts)
+
+	fields := make([]string, 0, len(texts))
+	for field := range texts {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	findings := make([]ToolFinding, 0)
+	for _, field := range fields {
+		text := texts[field]
+		if text == "" {
+			continue
+		}
+		if _, matched := i.scorer.Score(text); len(matched) > 0 {
+			findings = append(findings, ToolFinding{Check: PoisonInjection, Field: field})
+		}
+		if hasHiddenUnicode(text) {
+			findings = append(findings, ToolFinding{Check: PoisonHiddenUnicode, Field: field})
+		}
+		if len([]rune(text)) > i.MaxDescriptionLength {
+			findings = append(findings, ToolFinding{Check: PoisonExcessiveLength, Field: field})
+		}
+		if i.referencesAny(text, otherTools) {
+			findings = append(findings, ToolFinding{Check: PoisonToolReference, Field: field})
+		}
+	}
+	return findings
+}
+
+func collectDescriptions(value interface{}, path string, texts map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := path + "." + key
+			if text, ok := child.(string); ok && key == "description" {
+				texts[childPath] = text
+				continue
+			}
+			collectDescriptions(child, childPath, texts)
+		}
+	case []interface{}:
+		for idx, child := range v {
+			collectDescriptions(child, fmt.Sprintf("%s.%d", path, idx), texts)
+		}
+	}
+}
+
+// referencesAny reports whether text tells the model to call one of the named
+// tools: an imperative such as "call send_email" or "send the key to
+// send_email", or call syntax like "send_email(". A plain mention such as
+// "like send_email" is not a reference.
+func (i *ToolInspector) referencesAny(text string, names []string) bool {
+	for _, name := range names {
+		if name != "" && i.referencePattern(name).MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencePattern compiles the pattern for a tool name once. Names come from
+// requests, so the cache is reset when it reaches maxReferencePatterns.
+func (i *ToolInspector) referencePattern(name string) *regexp.Regexp {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if pattern, ok := i.references[name]; ok {
+		return pattern
+	}
+	if len(i.references) >= maxReferencePatterns {
+		i.references = make(map[string]*regexp.Regexp)
+	}
+	tool := regexp.QuoteMeta(name)
+	pattern := regexp.MustCompile(`(?i)` +
+		`\b(?:call|invoke|use|run|execute|trigger)(?:ing)?\s+(?:the\s+)?` + tool + `(?:$|[^a-z0-9_])` +
+		`|\b(?:pass|send|forward|give|provide)\s+(?:[^.\n]|\.\S){0,80}?\bto\s+(?:the\s+)?` + tool + `(?:$|[^a-z0-9_])` +
+		`|(?:^|[^a-z0-9_])` + tool + `\s*\(`)
+	i.references[name] = pattern
+	return pattern
+}
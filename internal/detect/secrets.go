@@ -0,0 +1,106 @@
+package detect
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	SecretAWSAccessKey = "aws_access_key"
+	SecretGitHubToken  = "github_token"
+	SecretPrivateKey   = "private_key"
+	SecretJWT          = "jwt"
+	SecretHighEntropy  = "high_entropy"
+)
+
+const (
+	defaultEntropyThreshold = 4.5
+	defaultEntropyMinLength = 32
+)
+
+var secretDetectors = []Detector{
+	{
+		Name:    SecretPrivateKey,
+		Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	},
+	{
+		Name:    SecretAWSAccessKey,
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Name:    SecretGitHubToken,
+		Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	{
+		Name:    SecretJWT,
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`),
+	},
+}
+
+// SecretDetectors returns the named credential detectors, or all of them
+// when no names are given. The high_entropy detector is built from
+// threshold (bits per character) and minLength, falling back to defaults
+// when they are zero.
+func SecretDetectors(threshold float64, minLength int, names ...string) ([]Detector, error) {
+	all := append(append([]Detector{}, secretDetectors...), HighEntropyDetector(threshold, minLength))
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	detectors := make([]Detector, 0, len(names))
+	for _, detector := range all {
+		if wanted[detector.Name] {
+			detectors = append(detectors, detector)
+			delete(wanted, detector.Name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("unknown secret detector: %s", name)
+	}
+	return detectors, nil
+}
+
+func HighEntropyDetector(threshold float64, minLength int) Detector {
+	if threshold <= 0 {
+		threshold = defaultEntropyThreshold
+	}
+	if minLength <= 0 {
+		minLength = defaultEntropyMinLength
+	}
+	return Detector{
+		Name:    SecretHighEntropy,
+		Pattern: regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9+/=_-]{%d,}`, minLength)),
+		Validate: func(value string) bool {
+			// Long identifiers made only of letters (or only of digits) are
+			// rarely credentials.
+			if !strings.ContainsAny(value, "0123456789") || strings.Trim(value, "0123456789") == "" {
+				return false
+			}
+			return ShannonEntropy(value) >= threshold
+		},
+	}
+}
+
+func ShannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
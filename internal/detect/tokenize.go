@@ -0,0 +1,86 @@
+package detect
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Tokenizer maps sensitive values to placeholder tokens. Tokens are an HMAC
+// of the value, so the same value gets the same token across requests for
+// as long as the key is unchanged.
+type Tokenizer struct {
+	key []byte
+}
+
+func NewTokenizer(key []byte) *Tokenizer {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &Tokenizer{key: key}
+}
+
+func (t *Tokenizer) Token(finding Finding) string {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(finding.Detector))
+	mac.Write([]byte{0})
+	mac.Write([]byte(finding.Value))
+	return fmt.Sprintf("<%s_%s>", strings.ToUpper(finding.Detector), hex.EncodeToString(mac.Sum(nil))[:12])
+}
+
+// Vault remembers the original value behind each token issued for one
+// request so the response can be detokenized.
+type Vault struct {
+	values map[string]string
+}
+
+func NewVault() *Vault {
+	return &Vault{values: make(map[string]string)}
+}
+
+func (v *Vault) Tokenize(t *Tokenizer, finding Finding) string {
+	token := t.Token(finding)
+	v.values[token] = finding.Value
+	return token
+}
+
+func (v *Vault) Empty() bool {
+	return len(v.values) == 0
+}
+
+func (v *Vault) Detokenize(text string) string {
+	return v.detokenize(text, func(value string) string { return value })
+}
+
+// DetokenizeJSON restores tokens inside a JSON document, escaping values so
+// they stay valid inside JSON strings.
+func (v *Vault) DetokenizeJSON(text string) string {
+	return v.detokenize(text, func(value string) string {
+		var b strings.Builder
+		for _, r := range value {
+			switch r {
+			case '"', '\\':
+				b.WriteRune('\\')
+				b.WriteRune(r)
+			default:
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	})
+}
+
+func (v *Vault) detokenize(text string, escape func(string) string) string {
+	if len(v.values) == 0 || !strings.Contains(text, "<") {
+		return text
+	}
+	pairs := make([]string, 0, len(v.values)*2)
+	for token, value := range v.values {
+		pairs = append(pairs, token, escape(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
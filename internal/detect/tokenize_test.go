@@ -0,0 +1,47 @@
+package detect
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTokenizer_StableTokens(t *testing.T) {
+	tokenizer := NewTokenizer([]byte("key"))
+	email := Finding{Detector: PIIEmail, Value: "jane@example.com"}
+
+	first := tokenizer.Token(email)
+	if first != tokenizer.Token(email) {
+		t.Errorf("tokens differ for the same value")
+	}
+	if !regexp.MustCompile(`^<EMAIL_[0-9a-f]{12}>$`).MatchString(first) {
+		t.Errorf("token = %q", first)
+	}
+	if first == NewTokenizer([]byte("other")).Token(email) {
+		t.Errorf("tokens should depend on the key")
+	}
+	if strings.Contains(first, "jane") {
+		t.Errorf("token leaks the value: %q", first)
+	}
+}
+
+func TestVault_RoundTrip(t *testing.T) {
+	tokenizer := NewTokenizer([]byte("key"))
+	scanner := NewScanner(Detector{Name: "quoted", Pattern: regexp.MustCompile(`a"b`)})
+	vault := NewVault()
+
+	text := `value a"b here`
+	findings := scanner.Scan(text)
+	redacted := Replace(text, findings, func(f Finding) string { return vault.Tokenize(tokenizer, f) })
+	if strings.Contains(redacted, `a"b`) {
+		t.Fatalf("redacted = %q", redacted)
+	}
+
+	if got := vault.Detokenize(redacted); got != text {
+		t.Errorf("Detokenize() = %q, want %q", got, text)
+	}
+	token := tokenizer.Token(findings[0])
+	if got := vault.DetokenizeJSON(`{"v":"` + token + `"}`); got != `{"v":"a\"b"}` {
+		t.Errorf("DetokenizeJSON() = %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/approval"
+	"github.com/alereyleyva/agent-guard/internal/pinning"
+)
+
+type AdminHandler struct {
+	token     string
+	approvals *approval.Store
+	pins      *pinning.Store
+	mux       *http.ServeMux
+}
+
+type AdminOption func(*AdminHandler)
+
+func WithAdminToolCatalog(pins *pinning.Store) AdminOption {
+	return func(h *AdminHandler) {
+		h.pins = pins
+	}
+}
+
+func NewAdminHandler(token string, approvals *approval.Store, opts ...AdminOption) *AdminHandler {
+	h := &AdminHandler{
+		token:     token,
+		approvals: approvals,
+		mux:       http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc("GET /admin/approvals", h.listApprovals)
+	h.mux.HandleFunc("POST /admin/approvals/{id}/approve", h.resolveApproval(approval.OutcomeApproved))
+	h.mux.HandleFunc("POST /admin/approvals/{id}/reject", h.resolveApproval(approval.OutcomeRejected))
+	if h.pins != nil {
+		h.mux.HandleFunc("GET /admin/tools", h.listTools)
+	}
+	return h
+}
+
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		writeJSONError(w, &FlowError{
+			StatusCode: http.StatusUnauthorized,
+			Message:    "missing or invalid admin token",
+			Type:       "authentication_error",
+			Code:       "unauthorized",
+		})
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *AdminHandler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func (h *AdminHandler) listApprovals(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"approvals": h.approvals.List(),
+	})
+}
+
+func (h *AdminHandler) listTools(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.pins.Catalog())
+}
+
+func (h *AdminHandler) resolveApproval(outcome string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "invalid JSON request", http.StatusBadRequest)
+			return
+		}
+
+		err := h.approvals.Resolve(r.PathValue("id"), approval.Resolution{Outcome: outcome, Reason: body.Reason})
+		if errors.Is(err, approval.ErrNotFound) {
+			writeJSONError(w, &FlowError{
+				StatusCode: http.StatusNotFound,
+				Message:    "approval request not found or already resolved",
+				Type:       "invalid_request_error",
+				Code:       "approval_not_found",
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"id":      r.PathValue("id"),
+			"outcome": outcome,
+		})
+	}
+}
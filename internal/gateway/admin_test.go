@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alereyleyva/agent-guard/internal/approval"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/pinning"
+)
+
+func TestAdminHandler_RequiresToken(t *testing.T) {
+	handler := NewAdminHandler("secret", approval.NewStore(time.Minute))
+
+	for _, auth := range []string{"", "Bearer wrong", "secret"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/approvals", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want %d", auth, w.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestAdminHandler_ListAndApprove(t *testing.T) {
+	store := approval.NewStore(time.Minute)
+	handler := NewAdminHandler("secret", store)
+	pending := store.Submit(approval.Request{TraceID: "trace-1", ToolName: "deploy"})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/approvals", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var listed struct {
+		Approvals []approval.Request `json:"approvals"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if len(listed.Approvals) != 1 || listed.Approvals[0].ID != pending.ID {
+		t.Fatalf("approvals = %#v", listed.Approvals)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/approvals/"+pending.ID+"/reject", bytes.NewBufferString(`{"reason":"not today"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reject status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	res := store.Wait(t.Context(), pending.ID)
+	if res.Outcome != approval.OutcomeRejected || res.Reason != "not today" {
+		t.Errorf("resolution = %#v", res)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/admin/approvals/"+pending.ID+"/approve", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second resolution status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminHandler_ToolCatalog(t *testing.T) {
+	pins := pinning.NewStore()
+	pins.Observe("agent-a", normalize.ToolFunction{Name: "read_file", Description: "Reads a file."}, true)
+	handler := NewAdminHandler("secret", approval.NewStore(time.Minute), WithAdminToolCatalog(pins))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/tools", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var catalog pinning.PinFile
+	if err := json.Unmarshal(w.Body.Bytes(), &catalog); err != nil {
+		t.Fatalf("unmarshal error = %v", err)
+	}
+	if w.Code != http.StatusOK || len(catalog.Tools) != 1 || catalog.Tools[0].Name != "read_file" {
+		t.Errorf("status = %d, catalog = %#v", w.Code, catalog)
+	}
+}
@@ -0,0 +1,76 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alereyleyva/agent-guard/internal/approval"
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+type pendingApproval struct {
+	index    int
+	toolCall normalize.ToolCall
+	decision policy.Decision
+}
+
+// resolveApprovals submits every held tool call at once and waits for all of
+// them, replacing each decision with the approval outcome.
+func (f *Flow) resolveApprovals(ctx context.Context, traceID, modelName string, pending []pendingApproval) []pendingApproval {
+	requests := make([]approval.Request, 0, len(pending))
+	for _, p := range pending {
+		req := f.approvals.Submit(approval.Request{
+			TraceID:    traceID,
+			ToolCallID: p.toolCall.ID,
+			ToolName:   p.toolCall.Function.Name,
+			Arguments:  p.toolCall.Function.Arguments,
+			RuleID:     p.decision.RuleID,
+		})
+		f.logger.Emit(
+			audit.NewEvent(traceID, audit.EventTypeApprovalRequested).
+				WithProvider(f.provider.Name()).
+				WithModel(modelName).
+				WithToolName(req.ToolName).
+				WithApprovalID(req.ID).
+				WithDecision(p.decision.Action, p.decision.RuleID, p.decision.Reason),
+		)
+		requests = append(requests, req)
+	}
+
+	resolved := make([]pendingApproval, 0, len(pending))
+	for i, req := range requests {
+		p := pending[i]
+		p.decision = f.awaitApproval(ctx, traceID, modelName, req)
+		resolved = append(resolved, p)
+	}
+	return resolved
+}
+
+func (f *Flow) awaitApproval(ctx context.Context, traceID, modelName string, req approval.Request) policy.Decision {
+	res := f.approvals.Wait(ctx, req.ID)
+
+	eventType := audit.EventTypeApprovalExpired
+	decision := policy.NewDenyDecision(req.RuleID, fmt.Sprintf("approval for tool %q %s", req.ToolName, res.Outcome))
+	switch res.Outcome {
+	case approval.OutcomeApproved:
+		eventType = audit.EventTypeApprovalGranted
+		decision = policy.NewAllowDecision(req.RuleID, fmt.Sprintf("approval for tool %q granted", req.ToolName))
+	case approval.OutcomeRejected:
+		eventType = audit.EventTypeApprovalRejected
+	}
+	if res.Reason != "" {
+		decision.Reason += ": " + res.Reason
+	}
+
+	f.logger.Emit(
+		audit.NewEvent(traceID, eventType).
+			WithProvider(f.provider.Name()).
+			WithModel(modelName).
+			WithToolName(req.ToolName).
+			WithApprovalID(req.ID).
+			WithDecision(decision.Action, decision.RuleID, decision.Reason),
+	)
+	return decision
+}
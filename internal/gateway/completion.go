@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/provider"
+)
+
+type chatCompletion struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []chatCompletionChoice `json:"choices"`
+	Usage   *normalize.Usage       `json:"usage,omitempty"`
+}
+
+type chatCompletionChoice struct {
+	Index        int                   `json:"index"`
+	Message      chatCompletionMessage `json:"message"`
+	FinishReason string                `json:"finish_reason"`
+}
+
+type chatCompletionMessage struct {
+	Role      string               `json:"role"`
+	Content   *string              `json:"content"`
+	ToolCalls []normalize.ToolCall `json:"tool_calls,omitempty"`
+}
+
+type chatCompletionError struct {
+	Error chatCompletionErrorBody `json:"error"`
+}
+
+type chatCompletionErrorBody struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Code    *string `json:"code"`
+}
+
+// NewUnsupportedStreamError rejects streaming for providers whose responses
+// the gateway renders itself, since their event streams are not translated
+// to server-sent events.
+func NewUnsupportedStreamError(providerName string) *FlowError {
+	return &FlowError{
+		StatusCode: http.StatusBadRequest,
+		Message:    fmt.Sprintf("stream is not supported for provider %s", providerName),
+		Type:       "invalid_request_error",
+		Code:       "unsupported_parameter",
+	}
+}
+
+func (f *Flow) nativeResponses() bool {
+	native, ok := f.provider.(provider.NativeResponder)
+	return ok && native.NativeResponses()
+}
+
+// renderChatCompletion parses the final upstream body of a native provider
+// and re-serializes it as a chat.completion object.
+func (f *Flow) renderChatCompletion(resp *http.Response, body []byte, traceID, model string) ([]byte, error) {
+	normalized, err := f.parseResponse(resp, body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing upstream response: %w", err)
+	}
+	return json.Marshal(newChatCompletion(normalized, traceID, model, time.Now()))
+}
+
+func newChatCompletion(resp normalize.NormalizedResponse, traceID, model string, created time.Time) chatCompletion {
+	id := resp.ID
+	if id == "" {
+		id = "chatcmpl-" + traceID
+	}
+	if resp.Model != "" {
+		model = resp.Model
+	}
+	finishReason := resp.FinishReason
+	if finishReason == "" {
+		finishReason = "stop"
+	}
+
+	message := chatCompletionMessage{Role: "assistant", ToolCalls: resp.ToolCalls}
+	if resp.Content != "" || len(resp.ToolCalls) == 0 {
+		content := resp.Content
+		message.Content = &content
+	}
+
+	return chatCompletion{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created.Unix(),
+		Model:   model,
+		Choices: []chatCompletionChoice{{Index: 0, Message: message, FinishReason: finishReason}},
+		Usage:   resp.Usage,
+	}
+}
+
+// renderChatCompletionError maps a native provider error, such as a Bedrock
+// {"message": ...} body with its x-amzn-ErrorType header, to the OpenAI error
+// object.
+func renderChatCompletionError(statusCode int, header http.Header, body []byte) ([]byte, error) {
+	var native struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &native); err == nil && native.Message != "" {
+		message = native.Message
+	}
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+
+	var code *string
+	if errorType, _, _ := strings.Cut(header.Get("X-Amzn-ErrorType"), ":"); errorType != "" {
+		code = &errorType
+	}
+	return json.Marshal(chatCompletionError{Error: chatCompletionErrorBody{
+		Message: message,
+		Type:    errorTypeForStatus(statusCode),
+		Code:    code,
+	}})
+}
+
+func errorTypeForStatus(statusCode int) string {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return "authentication_error"
+	case statusCode == http.StatusForbidden:
+		return "permission_error"
+	case statusCode == http.StatusNotFound:
+		return "not_found_error"
+	case statusCode == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case statusCode >= http.StatusInternalServerError:
+		return "api_error"
+	default:
+		return "invalid_request_error"
+	}
+}
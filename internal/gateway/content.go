@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+func (f *Flow) checkRequestContent(traceID string, req normalize.NormalizedRequest) ([]normalize.Message, error) {
+	var messages []normalize.Message
+	for i, msg := range req.Messages {
+		var blocked *policy.Decision
+		changed := false
+		masked := msg.MapText(func(text string) string {
+			result := f.policy.EvaluateContent(config.ContentTargetRequest, msg.Role, text)
+			for _, decision := range result.Decisions {
+				f.emitDecision(
+					audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+						WithProvider(f.provider.Name()).
+						WithModel(req.Model).
+						WithMessageIndex(i),
+					decision,
+				)
+			}
+			if decision, ok := result.Blocked(); ok && blocked == nil {
+				blocked = &decision
+			}
+			changed = changed || result.Content != text
+			return result.Content
+		})
+		if blocked != nil {
+			return nil, NewPolicyDeniedError(blocked.Reason)
+		}
+		if changed {
+			if messages == nil {
+				messages = append([]normalize.Message{}, req.Messages...)
+			}
+			messages[i] = masked
+		}
+	}
+
+	if messages == nil {
+		return req.Messages, nil
+	}
+	return messages, nil
+}
+
+// checkResponseContent evaluates the assistant text of every choice and,
+// when a mask rule fired, sets a content transform on edit so every choice is
+// masked.
+func (f *Flow) checkResponseContent(traceID, model string, resp normalize.NormalizedResponse, edit *normalize.ResponseEdit) error {
+	contents := resp.Contents
+	if contents == nil {
+		contents = []string{resp.Content}
+	}
+
+	masked := false
+	for _, content := range contents {
+		if content == "" {
+			continue
+		}
+		result := f.policy.EvaluateContent(config.ContentTargetResponse, "assistant", content)
+		for _, decision := range result.Decisions {
+			f.emitDecision(
+				audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+					WithProvider(f.provider.Name()).
+					WithModel(model),
+				decision,
+			)
+		}
+		if decision, blocked := result.Blocked(); blocked {
+			return NewPolicyDeniedError(decision.Reason)
+		}
+		masked = masked || result.Content != content
+	}
+	if masked {
+		edit.Content = chainTransforms(edit.Content, func(content string) string {
+			return f.policy.EvaluateContent(config.ContentTargetResponse, "assistant", content).Content
+		})
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+)
+
+func NewUnknownFieldError(reason string) *FlowError {
+	return &FlowError{
+		StatusCode: http.StatusBadRequest,
+		Message:    reason,
+		Type:       "invalid_request_error",
+		Code:       "unknown_parameter",
+	}
+}
+
+// filterExtraFields keeps the unmodeled request fields the policy lets
+// through. Other fields are dropped or reject the request, depending on the
+// unknown fields mode.
+func (f *Flow) filterExtraFields(traceID string, req normalize.NormalizedRequest) (map[string]json.RawMessage, error) {
+	if len(req.Extra) == 0 {
+		return req.Extra, nil
+	}
+
+	names := make([]string, 0, len(req.Extra))
+	for name := range req.Extra {
+		names = append(names, name)
+	}
+	allowed, err := f.allowedFields(traceID, req, names)
+	if err != nil {
+		return nil, err
+	}
+	kept := make(map[string]json.RawMessage, len(allowed))
+	for name := range allowed {
+		kept[name] = req.Extra[name]
+	}
+	return kept, nil
+}
+
+// filterMessageFields applies the same policy to the unmodeled fields of
+// each message, which are evaluated as "messages.<field>".
+func (f *Flow) filterMessageFields(traceID string, req normalize.NormalizedRequest) ([]normalize.Message, error) {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, msg := range req.Messages {
+		for name := range msg.Extra {
+			if field := "messages." + name; !seen[field] {
+				seen[field] = true
+				names = append(names, field)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return req.Messages, nil
+	}
+
+	allowed, err := f.allowedFields(traceID, req, names)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]normalize.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		if len(msg.Extra) > 0 {
+			extra := make(map[string]json.RawMessage, len(msg.Extra))
+			for name, value := range msg.Extra {
+				if allowed["messages."+name] {
+					extra[name] = value
+				}
+			}
+			msg.Extra = extra
+		}
+		messages[i] = msg
+	}
+	return messages, nil
+}
+
+// allowedFields evaluates each unmodeled field name in sorted order and
+// returns the ones the policy lets through, or an error when one is denied
+// in reject mode.
+func (f *Flow) allowedFields(traceID string, req normalize.NormalizedRequest, names []string) (map[string]bool, error) {
+	sort.Strings(names)
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		decision := f.policy.EvaluateRequestField(name)
+		f.emitDecision(
+			audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+				WithProvider(f.provider.Name()).
+				WithModel(req.Model),
+			decision,
+		)
+
+		if decision.IsAllowed() {
+			allowed[name] = true
+			continue
+		}
+		if f.policy.UnknownFieldsMode() == config.UnknownFieldsReject {
+			return nil, NewUnknownFieldError(decision.Reason)
+		}
+	}
+	return allowed, nil
+}
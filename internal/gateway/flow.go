@@ -4,21 +4,51 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/alereyleyva/agent-guard/internal/approval"
 	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/detect"
 	"github.com/alereyleyva/agent-guard/internal/normalize"
 	"github.com/alereyleyva/agent-guard/internal/policy"
 	"github.com/alereyleyva/agent-guard/internal/provider"
 )
 
 type Flow struct {
-	provider provider.Provider
-	policy   *policy.Engine
-	logger   audit.Logger
-	client   *http.Client
+	provider  provider.Provider
+	policy    *policy.Engine
+	logger    audit.Logger
+	client    *http.Client
+	approvals *approval.Store
+	pii       *PIIGuard
+	secrets   *SecretGuard
+	injection *InjectionGuard
+	poisoning *PoisoningGuard
+	pins      *toolPinning
+	limits    config.LimitsConfig
+	aliases   map[string]string
+}
+
+type FlowOption func(*Flow)
+
+func WithApprovals(store *approval.Store) FlowOption {
+	return func(f *Flow) {
+		f.approvals = store
+	}
+}
+
+// WithModelAliases resolves the requested model through aliases before
+// policy evaluation and the upstream call.
+func WithModelAliases(aliases map[string]string) FlowOption {
+	return func(f *Flow) {
+		f.aliases = aliases
+	}
 }
 
 type Result struct {
@@ -28,51 +58,287 @@ type Result struct {
 	StreamBody io.ReadCloser
 }
 
-func NewFlow(p provider.Provider, pol *policy.Engine, logger audit.Logger) *Flow {
-	return &Flow{
+func NewFlow(p provider.Provider, pol *policy.Engine, logger audit.Logger, opts ...FlowOption) *Flow {
+	f := &Flow{
 		provider: p,
 		policy:   pol,
 		logger:   logger,
 		client:   &http.Client{},
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 func (f *Flow) Process(ctx context.Context, req normalize.NormalizedRequest) (*Result, error) {
 	traceID := generateTraceID()
 	reqHash := f.hashRequest(req)
+	requestedModel := ""
+	if resolved, ok := f.aliases[req.Model]; ok {
+		requestedModel = req.Model
+		req.Model = resolved
+	}
+	in := policy.Input{
+		Model:          req.Model,
+		RequestedModel: requestedModel,
+		Provider:       f.provider.Name(),
+		Headers:        req.Headers,
+		MessageCount:   len(req.Messages),
+		Stream:         req.Stream,
+		Time:           time.Now(),
+	}
+	in.History = f.toolHistory(in, req)
 	f.logger.Emit(
 		audit.NewEvent(traceID, audit.EventTypeLLMRequest).
 			WithProvider(f.provider.Name()).
 			WithModel(req.Model).
+			WithRequestedModel(in.RequestedModel).
+			WithCaller(f.policy.Caller(in)).
 			WithHash(reqHash).
 			WithStream(req.Stream),
 	)
 
-	modelDecision := f.policy.EvaluateModel(req.Model)
-	f.logger.Emit(
+	if req.Stream && f.nativeResponses() {
+		return nil, NewUnsupportedStreamError(f.provider.Name())
+	}
+
+	if err := f.checkLimits(traceID, req); err != nil {
+		return nil, err
+	}
+
+	modelDecision := f.policy.EvaluateRequest(in)
+	f.emitDecision(
 		audit.NewEvent(traceID, audit.EventTypePolicyDecision).
 			WithProvider(f.provider.Name()).
 			WithModel(req.Model).
-			WithDecision(modelDecision.Action, modelDecision.RuleID, modelDecision.Reason),
+			WithRequestedModel(in.RequestedModel),
+		modelDecision,
 	)
 
 	if !modelDecision.IsAllowed() {
 		return nil, NewPolicyDeniedError(modelDecision.Reason)
 	}
 
+	req, err := f.checkModelParams(traceID, req, in)
+	if err != nil {
+		return nil, err
+	}
+
+	extra, err := f.filterExtraFields(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Extra = extra
+	messages, err := f.filterMessageFields(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Messages = messages
+
+	if err := f.checkImages(traceID, req, in); err != nil {
+		return nil, err
+	}
+
+	messages, err = f.checkToolHistory(traceID, req, in)
+	if err != nil {
+		return nil, err
+	}
+	req.Messages = messages
+	in.History = f.toolHistory(in, req)
+
+	messages, err = f.checkRequestContent(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Messages = messages
+
+	messages, err = f.screenToolResults(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Messages = messages
+
+	messages, err = f.checkRequestSecrets(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Messages = messages
+
+	messages, vault, err := f.redactPII(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Messages = messages
+
+	tools, err := f.filterDeclaredTools(traceID, req, in)
+	if err != nil {
+		return nil, err
+	}
+	req.Tools = tools
+
+	tools, err = f.inspectDeclaredTools(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.Tools = tools
+
+	if err := f.checkToolPins(traceID, req, in); err != nil {
+		return nil, err
+	}
+
+	toolChoice, err := f.reconcileToolChoice(traceID, req)
+	if err != nil {
+		return nil, err
+	}
+	req.ToolChoice = toolChoice
+
 	if req.Stream {
-		return f.processStreaming(ctx, traceID, req)
+		if err := f.checkStreaming(traceID, req, vault); err != nil {
+			return nil, err
+		}
+		return f.processStreaming(ctx, traceID, req, in)
+	}
+
+	return f.processNonStreaming(ctx, traceID, req, in, vault)
+}
+
+// toolHistory merges the session record with the tool calls in the request
+// messages. The session record is always kept; request calls are added
+// only where the request shows more calls to a tool than the session has
+// recorded, so replayed history is not counted twice and padding the
+// messages cannot hide recorded calls.
+func (f *Flow) toolHistory(in policy.Input, req normalize.NormalizedRequest) []string {
+	history := f.policy.SessionHistory(f.policy.Session(in))
+	recorded := make(map[string]int, len(history))
+	for _, name := range history {
+		recorded[name]++
+	}
+	for _, name := range req.ToolCallHistory() {
+		if recorded[name] > 0 {
+			recorded[name]--
+			continue
+		}
+		history = append(history, name)
+	}
+	return history
+}
+
+func (f *Flow) filterDeclaredTools(traceID string, req normalize.NormalizedRequest, in policy.Input) ([]normalize.Tool, error) {
+	if len(req.Tools) == 0 {
+		return req.Tools, nil
+	}
+
+	allowed := make([]normalize.Tool, 0, len(req.Tools))
+	for _, tool := range req.Tools {
+		toolName := tool.Function.Name
+		toolIn := in
+		toolIn.Tool = toolName
+		toolDecision := f.policy.EvaluateToolDeclaration(toolIn)
+		f.emitDecision(
+			audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+				WithProvider(f.provider.Name()).
+				WithModel(req.Model).
+				WithToolName(toolName),
+			toolDecision,
+		)
+
+		if toolDecision.IsAllowed() || toolDecision.RequiresApproval() {
+			allowed = append(allowed, tool)
+			continue
+		}
+		if f.policy.DeclaredToolsMode() == config.DeclaredToolsReject {
+			return nil, NewPolicyDeniedError(toolDecision.Reason)
+		}
+	}
+
+	return allowed, nil
+}
+
+// reconcileToolChoice checks tool_choice against the tools left after
+// filtering. A choice naming a tool that is no longer declared, or any
+// choice once no tools are left, would be refused upstream; it is dropped
+// or rejects the request, following the declared tools mode.
+func (f *Flow) reconcileToolChoice(traceID string, req normalize.NormalizedRequest) (*normalize.ToolChoice, error) {
+	choice := req.ToolChoice
+	if choice == nil {
+		return nil, nil
+	}
+
+	var reason string
+	switch {
+	case choice.Function != "" && !declaresTool(req.Tools, choice.Function):
+		reason = fmt.Sprintf("tool_choice names tool %q, which is not available", choice.Function)
+	case len(req.Tools) == 0:
+		reason = fmt.Sprintf("tool_choice %q is set but no tools are available", choice.Mode)
+	default:
+		return choice, nil
+	}
+
+	decision := f.policy.Finalize(policy.NewDenyDecision("TOOL_CHOICE_UNAVAILABLE", reason))
+	f.emitDecision(
+		audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+			WithProvider(f.provider.Name()).
+			WithModel(req.Model).
+			WithToolName(choice.Function),
+		decision,
+	)
+	if decision.IsAllowed() {
+		return choice, nil
+	}
+	if f.policy.DeclaredToolsMode() == config.DeclaredToolsReject {
+		return nil, NewPolicyDeniedError(reason)
+	}
+	return nil, nil
+}
+
+func declaresTool(tools []normalize.Tool, name string) bool {
+	for _, tool := range tools {
+		if tool.Function.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	return f.processNonStreaming(ctx, traceID, req)
+func NewUpstreamResponseError(err error) *FlowError {
+	return &FlowError{
+		StatusCode: http.StatusBadGateway,
+		Message:    fmt.Sprintf("upstream response could not be checked: %v", err),
+		Type:       "api_error",
+		Code:       "invalid_upstream_response",
+	}
 }
 
-func (f *Flow) processNonStreaming(ctx context.Context, traceID string, req normalize.NormalizedRequest) (*Result, error) {
+func (f *Flow) buildUpstreamRequest(ctx context.Context, req normalize.NormalizedRequest) (*http.Request, error) {
 	upstreamReq, err := f.provider.BuildUpstreamRequest(req)
+	if errors.Is(err, provider.ErrUnsupportedContent) {
+		return nil, &FlowError{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Type:       "invalid_request_error",
+			Code:       "unsupported_content",
+		}
+	}
+	if errors.Is(err, provider.ErrUnsupportedParameter) {
+		return nil, &FlowError{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+			Type:       "invalid_request_error",
+			Code:       "unsupported_parameter",
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("building upstream request: %w", err)
 	}
-	upstreamReq = upstreamReq.WithContext(ctx)
+	return upstreamReq.WithContext(ctx), nil
+}
+
+func (f *Flow) processNonStreaming(ctx context.Context, traceID string, req normalize.NormalizedRequest, in policy.Input, vault *detect.Vault) (*Result, error) {
+	upstreamReq, err := f.buildUpstreamRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := f.client.Do(upstreamReq)
 	if err != nil {
@@ -87,8 +353,14 @@ func (f *Flow) processNonStreaming(ctx context.Context, traceID string, req norm
 		return nil, fmt.Errorf("reading upstream response: %w", err)
 	}
 
+	// A successful response that cannot be parsed cannot be checked against
+	// tool policy, so it is not forwarded. Error responses carry no tool
+	// calls and are passed through.
 	normalizedResp, err := f.parseResponse(resp, body)
 	if err != nil {
+		if statusCode < http.StatusMultipleChoices {
+			return nil, NewUpstreamResponseError(err)
+		}
 		normalizedResp = normalize.NormalizedResponse{RawBody: body, Model: req.Model}
 	}
 	normalizedResp.RawBody = body
@@ -102,10 +374,16 @@ func (f *Flow) processNonStreaming(ctx context.Context, traceID string, req norm
 		audit.NewEvent(traceID, audit.EventTypeLLMResponse).
 			WithProvider(f.provider.Name()).
 			WithModel(modelName).
+			WithRequestedModel(in.RequestedModel).
 			WithHash(respHash),
 	)
 
-	for _, toolCall := range normalizedResp.ToolCalls {
+	edit := normalize.ResponseEdit{DropToolCalls: map[int]string{}, ReplaceArguments: map[int]string{}}
+	f.detokenizeResponse(vault, &normalizedResp, &edit)
+
+	needsApproval := make([]pendingApproval, 0)
+	maskSecrets := false
+	for i, toolCall := range normalizedResp.ToolCalls {
 		toolName := toolCall.Function.Name
 		f.logger.Emit(
 			audit.NewEvent(traceID, audit.EventTypeToolProposal).
@@ -114,14 +392,118 @@ func (f *Flow) processNonStreaming(ctx context.Context, traceID string, req norm
 				WithToolName(toolName),
 		)
 
-		toolDecision := f.policy.EvaluateTool(toolName)
-		f.logger.Emit(
-			audit.NewEvent(traceID, audit.EventTypePolicyDecision).
-				WithProvider(f.provider.Name()).
-				WithModel(modelName).
-				WithToolName(toolName).
-				WithDecision(toolDecision.Action, toolDecision.RuleID, toolDecision.Reason),
-		)
+		if f.policy.ToolValidationMode() != config.ToolValidationOff {
+			if decision, valid := validateToolCall(req.Tools, toolCall); !valid {
+				decision = f.policy.Finalize(decision)
+				f.emitDecision(
+					audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+						WithProvider(f.provider.Name()).
+						WithModel(modelName).
+						WithToolName(toolName).
+						WithToolCallID(toolCall.ID),
+					decision,
+				)
+				if !decision.IsAllowed() {
+					if err := f.rejectInvalidToolCall(&edit, i, toolName, decision); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+		}
+
+		secretDecision, blocked, masked := f.checkToolCallSecrets(traceID, modelName, toolCall)
+		if blocked {
+			if err := f.denyToolCall(&edit, i, toolName, secretDecision); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		maskSecrets = maskSecrets || masked
+
+		toolIn := in
+		toolIn.Tool = toolName
+		toolIn.Arguments = toolCall.Function.Arguments
+		toolIn.History = append(append([]string{}, in.History...), normalizedResp.ExtractToolNames()[:i]...)
+		toolDecision := f.policy.EvaluateToolCall(toolIn)
+		decisionEvent := audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+			WithProvider(f.provider.Name()).
+			WithModel(modelName).
+			WithToolName(toolName).
+			WithHistory(toolIn.History)
+		if toolDecision.Action == policy.ActionRewrite {
+			decisionEvent = decisionEvent.WithArgumentHashes(
+				audit.HashContent([]byte(toolCall.Function.Arguments)),
+				audit.HashContent([]byte(toolDecision.Arguments)),
+			)
+		}
+		f.emitDecision(decisionEvent, toolDecision)
+
+		if toolDecision.Action == policy.ActionRewrite {
+			if toolDecision.Arguments != toolCall.Function.Arguments {
+				edit.ReplaceArguments[i] = toolDecision.Arguments
+			}
+			continue
+		}
+		if toolDecision.IsAllowed() {
+			continue
+		}
+		if toolDecision.RequiresApproval() && f.approvals != nil {
+			needsApproval = append(needsApproval, pendingApproval{index: i, toolCall: toolCall, decision: toolDecision})
+			continue
+		}
+		if err := f.denyToolCall(&edit, i, toolName, toolDecision); err != nil {
+			return nil, err
+		}
+	}
+
+	if maskSecrets {
+		f.maskSecretArguments(&edit)
+	}
+
+	for _, p := range f.resolveApprovals(ctx, traceID, modelName, needsApproval) {
+		if p.decision.IsAllowed() {
+			continue
+		}
+		if err := f.denyToolCall(&edit, p.index, p.toolCall.Function.Name, p.decision); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := f.checkResponseContent(traceID, modelName, normalizedResp, &edit); err != nil {
+		return nil, err
+	}
+
+	returned := make([]string, 0, len(normalizedResp.ToolCalls))
+	for i, toolCall := range normalizedResp.ToolCalls {
+		if _, dropped := edit.DropToolCalls[i]; !dropped {
+			returned = append(returned, toolCall.Function.Name)
+		}
+	}
+	f.policy.RecordToolCalls(f.policy.Session(in), returned)
+
+	if !edit.IsEmpty() {
+		body, err = f.provider.RewriteResponse(body, edit)
+		if err != nil {
+			return nil, fmt.Errorf("rewriting upstream response: %w", err)
+		}
+		header.Del("Content-Length")
+	}
+
+	if f.nativeResponses() {
+		if statusCode < http.StatusMultipleChoices {
+			body, err = f.renderChatCompletion(resp, body, traceID, modelName)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			body, err = renderChatCompletionError(statusCode, header, body)
+			if err != nil {
+				return nil, err
+			}
+		}
+		header.Del("Content-Length")
+		header.Set("Content-Type", "application/json")
 	}
 
 	return &Result{
@@ -131,12 +513,68 @@ func (f *Flow) processNonStreaming(ctx context.Context, traceID string, req norm
 	}, nil
 }
 
-func (f *Flow) processStreaming(ctx context.Context, traceID string, req normalize.NormalizedRequest) (*Result, error) {
-	upstreamReq, err := f.provider.BuildUpstreamRequest(req)
+func (f *Flow) denyToolCall(edit *normalize.ResponseEdit, index int, toolName string, decision policy.Decision) error {
+	switch f.policy.ToolEnforcement() {
+	case config.ToolEnforcementRemove:
+		edit.DropToolCalls[index] = ""
+	case config.ToolEnforcementRefuse:
+		edit.DropToolCalls[index] = fmt.Sprintf("The call to tool %q was blocked by policy: %s", toolName, decision.Reason)
+	default:
+		return NewPolicyDeniedError(decision.Reason)
+	}
+	return nil
+}
+
+func NewStreamUnsupportedError(reason string) *FlowError {
+	return &FlowError{
+		StatusCode: http.StatusBadRequest,
+		Message:    reason,
+		Type:       "invalid_request_error",
+		Code:       "stream_unsupported",
+	}
+}
+
+// checkStreaming refuses a streamed response when the gateway would have to
+// check or change it: streams are piped through as they arrive, so tool call
+// policy, approvals, rewrites, validation, response content rules, secret
+// checks on arguments and PII detokenization only run on buffered responses.
+func (f *Flow) checkStreaming(traceID string, req normalize.NormalizedRequest, vault *detect.Vault) error {
+	var checks []string
+	if len(req.Tools) > 0 {
+		checks = append(checks, "tool calls")
+	}
+	if f.policy.ChecksResponseContent() {
+		checks = append(checks, "response content rules")
+	}
+	if f.pii != nil && f.pii.detokenize && vault != nil && !vault.Empty() {
+		checks = append(checks, "PII detokenization")
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	decision := f.policy.Finalize(policy.NewDenyDecision(
+		"STREAM_UNSUPPORTED",
+		fmt.Sprintf("stream is not supported when the response is checked for %s; retry without stream", strings.Join(checks, ", ")),
+	))
+	f.emitDecision(
+		audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+			WithProvider(f.provider.Name()).
+			WithModel(req.Model).
+			WithStream(true),
+		decision,
+	)
+	if !decision.IsAllowed() {
+		return NewStreamUnsupportedError(decision.Reason)
+	}
+	return nil
+}
+
+func (f *Flow) processStreaming(ctx context.Context, traceID string, req normalize.NormalizedRequest, in policy.Input) (*Result, error) {
+	upstreamReq, err := f.buildUpstreamRequest(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("building upstream request: %w", err)
+		return nil, err
 	}
-	upstreamReq = upstreamReq.WithContext(ctx)
 
 	resp, err := f.client.Do(upstreamReq)
 	if err != nil {
@@ -147,6 +585,7 @@ func (f *Flow) processStreaming(ctx context.Context, traceID string, req normali
 		audit.NewEvent(traceID, audit.EventTypeLLMResponse).
 			WithProvider(f.provider.Name()).
 			WithModel(req.Model).
+			WithRequestedModel(in.RequestedModel).
 			WithStream(true),
 	)
 
@@ -157,6 +596,24 @@ func (f *Flow) processStreaming(ctx context.Context, traceID string, req normali
 	}, nil
 }
 
+func (f *Flow) emitDecision(event audit.Event, decision policy.Decision) {
+	if len(decision.Tags) > 0 {
+		event = event.WithTags(decision.Tags)
+	}
+	for _, shadow := range decision.Monitored {
+		f.logger.Emit(
+			event.WithConstraint(shadow.Constraint).
+				WithShadow(true).
+				WithDecision(shadow.Action, shadow.RuleID, shadow.Reason),
+		)
+	}
+	f.logger.Emit(
+		event.WithConstraint(decision.Constraint).
+			WithShadow(decision.Shadow).
+			WithDecision(decision.Action, decision.RuleID, decision.Reason),
+	)
+}
+
 func (f *Flow) hashRequest(req normalize.NormalizedRequest) string {
 	data, _ := json.Marshal(req)
 	return audit.HashContent(data)
@@ -3,6 +3,7 @@ package gateway
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 
@@ -10,11 +11,26 @@ import (
 )
 
 type Handler struct {
-	flow *Flow
+	flow         *Flow
+	maxBodyBytes int64
 }
 
-func NewHandler(flow *Flow) *Handler {
-	return &Handler{flow: flow}
+type HandlerOption func(*Handler)
+
+// WithMaxBodyBytes rejects request bodies larger than n bytes. Zero means
+// no limit.
+func WithMaxBodyBytes(n int64) HandlerOption {
+	return func(h *Handler) {
+		h.maxBodyBytes = n
+	}
+}
+
+func NewHandler(flow *Flow, opts ...HandlerOption) *Handler {
+	h := &Handler{flow: flow}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -24,25 +40,27 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	defer r.Body.Close()
-	req, err := normalize.DecodeOpenAIRequest(r.Body)
+	body := io.Reader(r.Body)
+	if h.maxBodyBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, h.maxBodyBytes)
+	}
+	req, err := normalize.DecodeOpenAIRequest(body)
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeJSONError(w, NewLimitExceededError(fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit)))
+			return
+		}
 		http.Error(w, "invalid JSON request", http.StatusBadRequest)
 		return
 	}
+	req.Headers = r.Header.Clone()
 
 	result, err := h.flow.Process(r.Context(), req)
 	if err != nil {
 		var flowErr *FlowError
 		if errors.As(err, &flowErr) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(flowErr.StatusCode)
-			_ = json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": map[string]interface{}{
-					"message": flowErr.Message,
-					"type":    flowErr.Type,
-					"code":    flowErr.Code,
-				},
-			})
+			writeJSONError(w, flowErr)
 			return
 		}
 		http.Error(w, "internal server error", http.StatusInternalServerError)
@@ -63,6 +81,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(result.Body)
 }
 
+func writeJSONError(w http.ResponseWriter, flowErr *FlowError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(flowErr.StatusCode)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": flowErr.Message,
+			"type":    flowErr.Type,
+			"code":    flowErr.Code,
+		},
+	})
+}
+
 type FlowError struct {
 	StatusCode int
 	Message    string
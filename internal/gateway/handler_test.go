@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/alereyleyva/agent-guard/internal/audit"
@@ -93,3 +94,92 @@ func TestHandler_Success(t *testing.T) {
 		t.Errorf("response body = %s", w.Body.String())
 	}
 }
+
+func TestHandler_RuleDeniedByHeader(t *testing.T) {
+	logger := &captureLogger{}
+	pol := policy.NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "block-untrusted-client", Action: config.RuleActionDeny, Match: config.RuleMatch{Callers: []string{"untrusted"}}},
+		},
+		Models: config.ModelPolicy{Allow: []string{"gpt-4o"}},
+	})
+	handler := NewHandler(NewFlow(provider.NewOpenAI("https://api.openai.com", ""), pol, logger))
+
+	payload := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewBufferString(payload))
+	req.Header.Set("X-AgentGuard-Client", "untrusted")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if logger.events[0].Caller != "untrusted" {
+		t.Errorf("event[0].Caller = %q, want untrusted", logger.events[0].Caller)
+	}
+	if logger.events[1].RuleID != "block-untrusted-client" {
+		t.Errorf("event[1].RuleID = %q, want block-untrusted-client", logger.events[1].RuleID)
+	}
+}
+
+func TestHandler_RequestLimits(t *testing.T) {
+	deepSchema := `{"type":"object","properties":{"a":{"type":"object","properties":{"b":{"type":"array","items":{"type":"string"}}}}}}`
+	limits := config.LimitsConfig{
+		MaxMessages:     2,
+		MaxMessageChars: 20,
+		MaxTools:        1,
+		MaxSchemaDepth:  4,
+		MaxPromptChars:  30,
+	}
+
+	tests := []struct {
+		name     string
+		payload  string
+		wantRule string
+	}{
+		{"body too large", `{"model":"gpt-4o","messages":[{"role":"user","content":"` + strings.Repeat("x", 512) + `"}]}`, ""},
+		{"too many messages", `{"model":"gpt-4o","messages":[{"role":"user","content":"a"},{"role":"user","content":"b"},{"role":"user","content":"c"}]}`, "LIMIT_MAX_MESSAGES"},
+		{"message too long", `{"model":"gpt-4o","messages":[{"role":"user","content":"` + strings.Repeat("x", 21) + `"}]}`, "LIMIT_MAX_MESSAGE_CHARS"},
+		{"prompt too long", `{"model":"gpt-4o","messages":[{"role":"user","content":"` + strings.Repeat("x", 16) + `"},{"role":"user","content":"` + strings.Repeat("y", 16) + `"}]}`, "LIMIT_MAX_PROMPT_CHARS"},
+		{"too many tools", `{"model":"gpt-4o","messages":[],"tools":[{"type":"function","function":{"name":"a"}},{"type":"function","function":{"name":"b"}}]}`, "LIMIT_MAX_TOOLS"},
+		{"schema too deep", `{"model":"gpt-4o","messages":[],"tools":[{"type":"function","function":{"name":"a","parameters":` + deepSchema + `}}]}`, "LIMIT_MAX_SCHEMA_DEPTH"},
+	}
+
+	for _, tt := range tests {
+		for _, dryRun := range []bool{false, true} {
+			name := tt.name
+			if dryRun {
+				name += " in dry run"
+			}
+			t.Run(name, func(t *testing.T) {
+				logger := &captureLogger{}
+				pol := policy.NewEngine(config.PolicyConfig{DryRun: dryRun, Models: config.ModelPolicy{Allow: []string{"gpt-4o"}}})
+				flow := NewFlow(provider.NewOpenAI("https://api.openai.com", ""), pol, logger, WithLimits(limits))
+				handler := NewHandler(flow, WithMaxBodyBytes(512))
+
+				req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewBufferString(tt.payload))
+				w := httptest.NewRecorder()
+
+				handler.ServeHTTP(w, req)
+
+				if w.Code != http.StatusRequestEntityTooLarge {
+					t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusRequestEntityTooLarge, w.Body.String())
+				}
+				var decoded map[string]map[string]string
+				if err := json.Unmarshal(w.Body.Bytes(), &decoded); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				if decoded["error"]["code"] != "request_too_large" {
+					t.Errorf("error.code = %q, want request_too_large", decoded["error"]["code"])
+				}
+				if tt.wantRule == "" {
+					return
+				}
+				if last := logger.events[len(logger.events)-1]; last.RuleID != tt.wantRule || last.Shadow {
+					t.Errorf("last event = %#v, want enforced rule %s", last, tt.wantRule)
+				}
+			})
+		}
+	}
+}
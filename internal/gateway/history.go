@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+// checkToolHistory re-evaluates the assistant tool calls and tool results the
+// client claims happened earlier, so a call policy would not have allowed as
+// is cannot be smuggled back in as conversation history. Calls are judged on
+// the tool and its arguments only; see policy.Engine.EvaluateToolHistory.
+func (f *Flow) checkToolHistory(traceID string, req normalize.NormalizedRequest, in policy.Input) ([]normalize.Message, error) {
+	denied := make(map[string]bool)
+	known := make(map[string]bool)
+	stripped := false
+	reject := f.policy.ToolHistoryMode() == config.ToolHistoryReject
+
+	messages := make([]normalize.Message, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			kept := make([]normalize.ToolCall, 0, len(msg.ToolCalls))
+			for _, toolCall := range msg.ToolCalls {
+				toolIn := in
+				toolIn.Tool = toolCall.Function.Name
+				toolIn.Arguments = toolCall.Function.Arguments
+				decision := f.policy.EvaluateToolHistory(toolIn)
+				if !f.verifiedHistoryCall(decision, toolCall) {
+					decision = unverifiedHistoryCall(decision)
+				}
+
+				if decision.Action == policy.ActionDeny || len(decision.Monitored) > 0 {
+					f.emitHistoryDecision(traceID, req.Model, toolCall.Function.Name, toolCall.ID, decision)
+				}
+				if decision.Action != policy.ActionDeny {
+					known[toolCall.ID] = true
+					kept = append(kept, toolCall)
+					continue
+				}
+				if reject {
+					return nil, NewPolicyDeniedError(fmt.Sprintf("conversation history contains a denied tool call: %s", decision.Reason))
+				}
+				denied[toolCall.ID] = true
+				stripped = true
+			}
+			msg.ToolCalls = kept
+			if len(kept) == 0 && msg.Content == "" && len(msg.Parts) == 0 {
+				continue
+			}
+		case msg.Role == "tool":
+			if denied[msg.ToolCallID] {
+				continue
+			}
+			if !known[msg.ToolCallID] {
+				decision := policy.NewDenyDecision(
+					"TOOL_HISTORY_ORPHAN",
+					fmt.Sprintf("tool result %q has no matching assistant tool call", msg.ToolCallID),
+				)
+				f.emitHistoryDecision(traceID, req.Model, "", msg.ToolCallID, decision)
+				if reject {
+					return nil, NewPolicyDeniedError(decision.Reason)
+				}
+				stripped = true
+				continue
+			}
+		}
+		messages = append(messages, msg)
+	}
+
+	if !stripped {
+		return req.Messages, nil
+	}
+	return messages, nil
+}
+
+// verifiedHistoryCall reports whether a replayed tool call is one the gateway
+// would have returned as is: allowed outright, rewritten to exactly these
+// arguments, or approved by an operator with these arguments.
+func (f *Flow) verifiedHistoryCall(decision policy.Decision, toolCall normalize.ToolCall) bool {
+	switch decision.Action {
+	case policy.ActionAllow:
+		return true
+	case policy.ActionRewrite:
+		return equalJSON(decision.Arguments, toolCall.Function.Arguments)
+	case policy.ActionRequireApproval:
+		if f.approvals == nil {
+			return false
+		}
+		approved, ok := f.approvals.Approved(toolCall.ID)
+		return ok && approved.ToolName == toolCall.Function.Name && equalJSON(approved.Arguments, toolCall.Function.Arguments)
+	default:
+		return false
+	}
+}
+
+// unverifiedHistoryCall turns a non-allow outcome into the deny that strips or
+// rejects the replayed call.
+func unverifiedHistoryCall(decision policy.Decision) policy.Decision {
+	decision.Reason = fmt.Sprintf("unverified %s outcome: %s", decision.Action, decision.Reason)
+	decision.Action = policy.ActionDeny
+	decision.Arguments = ""
+	return decision
+}
+
+func equalJSON(a, b string) bool {
+	var left, right any
+	if json.Unmarshal([]byte(a), &left) != nil || json.Unmarshal([]byte(b), &right) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func (f *Flow) emitHistoryDecision(traceID, model, toolName, toolCallID string, decision policy.Decision) {
+	f.emitDecision(
+		audit.NewEvent(traceID, audit.EventTypeToolHistory).
+			WithProvider(f.provider.Name()).
+			WithModel(model).
+			WithToolName(toolName).
+			WithToolCallID(toolCallID),
+		decision,
+	)
+}
@@ -0,0 +1,31 @@
+package gateway
+
+import (
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+// checkImages evaluates every image content part in the request against
+// the image policy.
+func (f *Flow) checkImages(traceID string, req normalize.NormalizedRequest, in policy.Input) error {
+	for i, msg := range req.Messages {
+		for _, image := range msg.Images() {
+			decision := f.policy.EvaluateImage(in, policy.Image{
+				Remote: image.IsRemote(),
+				Size:   image.DecodedSize(),
+			})
+			f.emitDecision(
+				audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+					WithProvider(f.provider.Name()).
+					WithModel(req.Model).
+					WithMessageIndex(i),
+				decision,
+			)
+			if !decision.IsAllowed() {
+				return NewPolicyDeniedError(decision.Reason)
+			}
+		}
+	}
+	return nil
+}
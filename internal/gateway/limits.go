@@ -0,0 +1,98 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+func WithLimits(limits config.LimitsConfig) FlowOption {
+	return func(f *Flow) {
+		f.limits = limits
+	}
+}
+
+func NewLimitExceededError(reason string) *FlowError {
+	return &FlowError{
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Message:    reason,
+		Type:       "invalid_request_error",
+		Code:       "request_too_large",
+	}
+}
+
+// checkLimits rejects requests over one of the configured size caps before
+// any other work is done on them. The caps protect the gateway itself, so
+// they apply in dry run too.
+func (f *Flow) checkLimits(traceID string, req normalize.NormalizedRequest) error {
+	ruleID, reason := exceededLimit(f.limits, req)
+	if ruleID == "" {
+		return nil
+	}
+
+	f.emitDecision(
+		audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+			WithProvider(f.provider.Name()).
+			WithModel(req.Model),
+		policy.NewDenyDecision(ruleID, reason),
+	)
+	return NewLimitExceededError(reason)
+}
+
+func exceededLimit(limits config.LimitsConfig, req normalize.NormalizedRequest) (string, string) {
+	if limits.MaxMessages > 0 && len(req.Messages) > limits.MaxMessages {
+		return "LIMIT_MAX_MESSAGES", fmt.Sprintf("request has %d messages, the limit is %d", len(req.Messages), limits.MaxMessages)
+	}
+
+	total := 0
+	for i, msg := range req.Messages {
+		chars := msg.ContentChars()
+		if limits.MaxMessageChars > 0 && chars > limits.MaxMessageChars {
+			return "LIMIT_MAX_MESSAGE_CHARS", fmt.Sprintf("message %d has %d characters, the limit is %d", i, chars, limits.MaxMessageChars)
+		}
+		total += chars
+		for _, toolCall := range msg.ToolCalls {
+			total += utf8.RuneCountInString(toolCall.Function.Arguments)
+		}
+	}
+	if limits.MaxPromptChars > 0 && total > limits.MaxPromptChars {
+		return "LIMIT_MAX_PROMPT_CHARS", fmt.Sprintf("request has %d prompt characters, the limit is %d", total, limits.MaxPromptChars)
+	}
+
+	if limits.MaxTools > 0 && len(req.Tools) > limits.MaxTools {
+		return "LIMIT_MAX_TOOLS", fmt.Sprintf("request declares %d tools, the limit is %d", len(req.Tools), limits.MaxTools)
+	}
+	if limits.MaxSchemaDepth > 0 {
+		for _, tool := range req.Tools {
+			if depth := schemaDepth(tool.Function.Parameters); depth > limits.MaxSchemaDepth {
+				return "LIMIT_MAX_SCHEMA_DEPTH", fmt.Sprintf("tool %q schema is nested %d levels deep, the limit is %d", tool.Function.Name, depth, limits.MaxSchemaDepth)
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// schemaDepth counts the nesting of JSON objects and arrays in a decoded
+// schema; a flat object is depth 1.
+func schemaDepth(value interface{}) int {
+	deepest := 0
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			deepest = max(deepest, schemaDepth(child))
+		}
+	case []interface{}:
+		for _, child := range v {
+			deepest = max(deepest, schemaDepth(child))
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
@@ -0,0 +1,43 @@
+package gateway
+
+import (
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+// checkModelParams applies the model_params rules to the sampling fields of
+// req and returns the request with any clamps applied. max_tokens and
+// max_completion_tokens are both held to the max_tokens ceiling.
+func (f *Flow) checkModelParams(traceID string, req normalize.NormalizedRequest, in policy.Input) (normalize.NormalizedRequest, error) {
+	params := policy.ModelParams{
+		MaxTokens:           req.MaxTokens,
+		MaxCompletionTokens: req.MaxCompletionTokens,
+		Temperature:         req.Temperature,
+		TopP:                req.TopP,
+		N:                   req.N,
+	}
+	if req.ResponseFormat != nil {
+		params.ResponseFormat = req.ResponseFormat.Type
+	}
+
+	result := f.policy.EvaluateModelParams(in, params)
+	for _, decision := range result.Decisions {
+		f.emitDecision(
+			audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+				WithProvider(f.provider.Name()).
+				WithModel(req.Model),
+			decision,
+		)
+	}
+	if decision, blocked := result.Blocked(); blocked {
+		return req, NewPolicyDeniedError(decision.Reason)
+	}
+
+	req.MaxTokens = result.Params.MaxTokens
+	req.MaxCompletionTokens = result.Params.MaxCompletionTokens
+	req.Temperature = result.Params.Temperature
+	req.TopP = result.Params.TopP
+	req.N = result.Params.N
+	return req, nil
+}
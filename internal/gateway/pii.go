@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/detect"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+const piiRuleID = "PII_DETECTED"
+
+type PIIGuard struct {
+	action     string
+	detokenize bool
+	scanner    *detect.Scanner
+	tokenizer  *detect.Tokenizer
+}
+
+func NewPIIGuard(cfg config.PIIConfig) (*PIIGuard, error) {
+	detectors, err := detect.PIIDetectors(cfg.Detectors...)
+	if err != nil {
+		return nil, err
+	}
+	action := cfg.Action
+	if action == "" {
+		action = config.PIIActionRedact
+	}
+	return &PIIGuard{
+		action:     action,
+		detokenize: cfg.Detokenize,
+		scanner:    detect.NewScanner(detectors...),
+		tokenizer:  detect.NewTokenizer([]byte(cfg.Key)),
+	}, nil
+}
+
+func WithPII(guard *PIIGuard) FlowOption {
+	return func(f *Flow) {
+		f.pii = guard
+	}
+}
+
+// redactPII scans message content and the arguments of historical tool
+// calls. Depending on the action it blocks the request, replaces each hit
+// with a placeholder token recorded in the returned vault, or only audits.
+func (f *Flow) redactPII(traceID string, req normalize.NormalizedRequest) ([]normalize.Message, *detect.Vault, error) {
+	vault := detect.NewVault()
+	if f.pii == nil {
+		return req.Messages, vault, nil
+	}
+
+	enforce := !f.policy.DryRun()
+	messages := make([]normalize.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		var counts map[string]int
+		msg = msg.MapText(func(text string) string {
+			redacted, textCounts := f.pii.redact(text, vault, enforce)
+			counts = detect.MergeCounts(counts, textCounts)
+			return redacted
+		})
+		if len(msg.ToolCalls) > 0 {
+			toolCalls := make([]normalize.ToolCall, len(msg.ToolCalls))
+			for j, toolCall := range msg.ToolCalls {
+				var argCounts map[string]int
+				toolCall.Function.Arguments, argCounts = f.pii.redact(toolCall.Function.Arguments, vault, enforce)
+				counts = detect.MergeCounts(counts, argCounts)
+				toolCalls[j] = toolCall
+			}
+			msg.ToolCalls = toolCalls
+		}
+		messages[i] = msg
+		if len(counts) == 0 {
+			continue
+		}
+
+		decision := f.pii.decision(counts)
+		decision.Shadow = !enforce && f.pii.action != config.PIIActionMonitor
+		f.logger.Emit(
+			audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+				WithProvider(f.provider.Name()).
+				WithModel(req.Model).
+				WithMessageIndex(i).
+				WithDetections(counts).
+				WithShadow(decision.Shadow).
+				WithDecision(decision.Action, decision.RuleID, decision.Reason),
+		)
+		if decision.Action == policy.ActionDeny && enforce {
+			return nil, nil, NewPolicyDeniedError(decision.Reason)
+		}
+	}
+
+	return messages, vault, nil
+}
+
+func (g *PIIGuard) redact(text string, vault *detect.Vault, enforce bool) (string, map[string]int) {
+	if text == "" {
+		return text, nil
+	}
+	findings := g.scanner.Scan(text)
+	if len(findings) == 0 {
+		return text, nil
+	}
+	if enforce && g.action == config.PIIActionRedact {
+		text = detect.Replace(text, findings, func(finding detect.Finding) string {
+			return vault.Tokenize(g.tokenizer, finding)
+		})
+	}
+	return text, detect.Counts(findings)
+}
+
+func (g *PIIGuard) decision(counts map[string]int) policy.Decision {
+	reason := fmt.Sprintf("request contains personal data (%s)", formatCounts(counts))
+	switch g.action {
+	case config.PIIActionBlock:
+		return policy.NewDenyDecision(piiRuleID, reason)
+	case config.PIIActionRedact:
+		return policy.Decision{Action: policy.ActionMask, RuleID: piiRuleID, Reason: reason}
+	default:
+		return policy.Decision{Action: policy.ActionFlag, RuleID: piiRuleID, Reason: reason}
+	}
+}
+
+// detokenizeResponse restores placeholder values in the parsed response and
+// registers the same transforms on edit for the response body.
+func (f *Flow) detokenizeResponse(vault *detect.Vault, resp *normalize.NormalizedResponse, edit *normalize.ResponseEdit) {
+	if f.pii == nil || !f.pii.detokenize || vault == nil || vault.Empty() {
+		return
+	}
+
+	resp.Content = vault.Detokenize(resp.Content)
+	for i := range resp.Contents {
+		resp.Contents[i] = vault.Detokenize(resp.Contents[i])
+	}
+	for i := range resp.ToolCalls {
+		resp.ToolCalls[i].Function.Arguments = vault.DetokenizeJSON(resp.ToolCalls[i].Function.Arguments)
+	}
+	edit.Content = chainTransforms(edit.Content, vault.Detokenize)
+	edit.Arguments = chainTransforms(edit.Arguments, vault.DetokenizeJSON)
+}
+
+func chainTransforms(first, next func(string) string) func(string) string {
+	if first == nil {
+		return next
+	}
+	return func(s string) string {
+		return next(first(s))
+	}
+}
+
+func formatCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/pinning"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+type toolPinning struct {
+	store   *pinning.Store
+	onDrift string
+	pinNew  bool
+}
+
+func WithToolPinning(store *pinning.Store, cfg config.PinningConfig) FlowOption {
+	return func(f *Flow) {
+		onDrift := cfg.OnDrift
+		if onDrift == "" {
+			onDrift = config.PinDriftAlert
+		}
+		f.pins = &toolPinning{store: store, onDrift: onDrift, pinNew: cfg.PinsNew()}
+	}
+}
+
+// checkToolPins compares every declared tool definition with its pinned
+// hash so a definition that changes after review (a "rug pull") is caught.
+// Pins learned on first use belong to the caller; dry run never writes pins.
+func (f *Flow) checkToolPins(traceID string, req normalize.NormalizedRequest, in policy.Input) error {
+	if f.pins == nil {
+		return nil
+	}
+
+	enforce := !f.policy.DryRun()
+	caller := f.policy.Caller(in)
+	for _, tool := range req.Tools {
+		check := f.pins.store.Observe(caller, tool.Function, f.pins.pinNew && enforce)
+
+		var decision policy.Decision
+		switch check.Status {
+		case pinning.StatusNew:
+			wouldPin := !enforce && f.pins.pinNew
+			if !check.Pinned && !wouldPin {
+				continue
+			}
+			decision = policy.NewAllowDecision("TOOL_PINNED", fmt.Sprintf("tool %q pinned on first use", tool.Function.Name))
+			decision.Shadow = !enforce
+		case pinning.StatusDrifted:
+			reason := fmt.Sprintf("tool %q definition drifted from pinned %s", tool.Function.Name, check.PinnedHash)
+			switch f.pins.onDrift {
+			case config.PinDriftDeny:
+				decision = policy.NewDenyDecision("TOOL_SCHEMA_DRIFT", reason)
+				decision.Shadow = !enforce
+			case config.PinDriftRepin:
+				decision = policy.NewAllowDecision("TOOL_SCHEMA_DRIFT", reason+"; re-pinned")
+				switch {
+				case !enforce:
+					decision.Shadow = true
+				case !f.pins.store.Repin(caller, tool.Function):
+					decision = policy.Decision{Action: policy.ActionFlag, RuleID: "TOOL_SCHEMA_DRIFT", Reason: reason + "; not re-pinned"}
+				}
+			default:
+				decision = policy.Decision{Action: policy.ActionFlag, RuleID: "TOOL_SCHEMA_DRIFT", Reason: reason}
+			}
+		default:
+			continue
+		}
+
+		f.logger.Emit(
+			audit.NewEvent(traceID, audit.EventTypeToolPin).
+				WithProvider(f.provider.Name()).
+				WithModel(req.Model).
+				WithCaller(caller).
+				WithToolName(tool.Function.Name).
+				WithHash(check.Hash).
+				WithShadow(decision.Shadow).
+				WithDecision(decision.Action, decision.RuleID, decision.Reason),
+		)
+		if decision.Action == policy.ActionDeny && !decision.Shadow {
+			return NewPolicyDeniedError(decision.Reason)
+		}
+	}
+	return nil
+}
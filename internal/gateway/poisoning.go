@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/detect"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+const poisoningRuleID = "TOOL_POISONING"
+
+type PoisoningGuard struct {
+	action    string
+	inspector *detect.ToolInspector
+}
+
+func NewPoisoningGuard(cfg config.PoisoningConfig) *PoisoningGuard {
+	action := cfg.Action
+	if action == "" {
+		action = config.PoisoningActionStrip
+	}
+	return &PoisoningGuard{action: action, inspector: detect.NewToolInspector(cfg.MaxDescriptionLength)}
+}
+
+func WithToolPoisoning(guard *PoisoningGuard) FlowOption {
+	return func(f *Flow) {
+		f.poisoning = guard
+	}
+}
+
+func (g *PoisoningGuard) decision(toolName string, findings []detect.ToolFinding) policy.Decision {
+	seen := make(map[string]bool)
+	parts := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		part := finding.Check + " in " + finding.Field
+		if !seen[part] {
+			seen[part] = true
+			parts = append(parts, part)
+		}
+	}
+	sort.Strings(parts)
+	reason := fmt.Sprintf("tool %q definition looks poisoned: %s", toolName, strings.Join(parts, "; "))
+
+	switch g.action {
+	case config.PoisoningActionBlock:
+		return policy.NewDenyDecision(poisoningRuleID, reason)
+	case config.PoisoningActionStrip:
+		return policy.Decision{Action: policy.ActionMask, RuleID: poisoningRuleID, Reason: reason}
+	default:
+		return policy.Decision{Action: policy.ActionFlag, RuleID: poisoningRuleID, Reason: reason}
+	}
+}
+
+// inspectDeclaredTools looks for hostile instructions in tool definitions
+// and blocks the request or strips the offending definitions.
+func (f *Flow) inspectDeclaredTools(traceID string, req normalize.NormalizedRequest) ([]normalize.Tool, error) {
+	if f.poisoning == nil || len(req.Tools) == 0 {
+		return req.Tools, nil
+	}
+
+	names := make([]string, 0, len(req.Tools))
+	for _, tool := range req.Tools {
+		names = append(names, tool.Function.Name)
+	}
+
+	enforce := !f.policy.DryRun()
+	kept := make([]normalize.Tool, 0, len(req.Tools))
+	for i, tool := range req.Tools {
+		others := append(append([]string{}, names[:i]...), names[i+1:]...)
+		findings := f.poisoning.inspector.Inspect(tool.Function.Description, tool.Function.Parameters, others)
+		if len(findings) == 0 {
+			kept = append(kept, tool)
+			continue
+		}
+
+		counts := make(map[string]int)
+		for _, finding := range findings {
+			counts[finding.Check]++
+		}
+		decision := f.poisoning.decision(tool.Function.Name, findings)
+		decision.Shadow = !enforce && f.poisoning.action != config.PoisoningActionMonitor
+		f.logger.Emit(
+			audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+				WithProvider(f.provider.Name()).
+				WithModel(req.Model).
+				WithToolName(tool.Function.Name).
+				WithDetections(counts).
+				WithShadow(decision.Shadow).
+				WithDecision(decision.Action, decision.RuleID, decision.Reason),
+		)
+
+		if decision.Shadow || decision.Action == policy.ActionFlag {
+			kept = append(kept, tool)
+			continue
+		}
+		if decision.Action == policy.ActionDeny {
+			return nil, NewPolicyDeniedError(decision.Reason)
+		}
+	}
+	return kept, nil
+}
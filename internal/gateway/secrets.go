@@ -0,0 +1,176 @@
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/detect"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+type SecretGuard struct {
+	action  string
+	scanner *detect.Scanner
+}
+
+func NewSecretGuard(cfg config.SecretsConfig) (*SecretGuard, error) {
+	detectors, err := detect.SecretDetectors(cfg.EntropyThreshold, cfg.EntropyMinLength, cfg.Detectors...)
+	if err != nil {
+		return nil, err
+	}
+	action := cfg.Action
+	if action == "" {
+		action = config.SecretsActionBlock
+	}
+	return &SecretGuard{action: action, scanner: detect.NewScanner(detectors...)}, nil
+}
+
+func WithSecrets(guard *SecretGuard) FlowOption {
+	return func(f *Flow) {
+		f.secrets = guard
+	}
+}
+
+func secretRuleID(detector string) string {
+	return "SECRET_" + strings.ToUpper(detector)
+}
+
+// scan returns text with secrets masked (when the action is mask and it is
+// enforced) and one decision per detector that fired.
+func (g *SecretGuard) scan(text, where string, enforce bool) (string, []policy.Decision, map[string]int) {
+	if text == "" {
+		return text, nil, nil
+	}
+	findings := g.scanner.Scan(text)
+	if len(findings) == 0 {
+		return text, nil, nil
+	}
+	if enforce && g.action == config.SecretsActionMask {
+		text = detect.Replace(text, findings, func(finding detect.Finding) string {
+			return "[REDACTED:" + strings.ToUpper(finding.Detector) + "]"
+		})
+	}
+
+	counts := detect.Counts(findings)
+	detectors := make([]string, 0, len(counts))
+	for name := range counts {
+		detectors = append(detectors, name)
+	}
+	sort.Strings(detectors)
+
+	decisions := make([]policy.Decision, 0, len(detectors))
+	for _, name := range detectors {
+		reason := fmt.Sprintf("%s contains a credential (%s)", where, name)
+		var decision policy.Decision
+		switch g.action {
+		case config.SecretsActionBlock:
+			decision = policy.NewDenyDecision(secretRuleID(name), reason)
+		case config.SecretsActionMask:
+			decision = policy.Decision{Action: policy.ActionMask, RuleID: secretRuleID(name), Reason: reason}
+		default:
+			decision = policy.Decision{Action: policy.ActionFlag, RuleID: secretRuleID(name), Reason: reason}
+		}
+		decision.Shadow = !enforce && g.action != config.SecretsActionMonitor
+		decisions = append(decisions, decision)
+	}
+	return text, decisions, counts
+}
+
+func (f *Flow) emitSecretDecisions(event audit.Event, decisions []policy.Decision, counts map[string]int) {
+	for _, decision := range decisions {
+		detector := strings.ToLower(strings.TrimPrefix(decision.RuleID, "SECRET_"))
+		f.logger.Emit(
+			event.WithDetections(map[string]int{detector: counts[detector]}).
+				WithShadow(decision.Shadow).
+				WithDecision(decision.Action, decision.RuleID, decision.Reason),
+		)
+	}
+}
+
+func blockingDecision(decisions []policy.Decision) (policy.Decision, bool) {
+	for _, decision := range decisions {
+		if decision.Action == policy.ActionDeny && !decision.Shadow {
+			return decision, true
+		}
+	}
+	return policy.Decision{}, false
+}
+
+// checkRequestSecrets scans message content and historical tool arguments.
+func (f *Flow) checkRequestSecrets(traceID string, req normalize.NormalizedRequest) ([]normalize.Message, error) {
+	if f.secrets == nil {
+		return req.Messages, nil
+	}
+
+	enforce := !f.policy.DryRun()
+	messages := make([]normalize.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		event := audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+			WithProvider(f.provider.Name()).
+			WithModel(req.Model).
+			WithMessageIndex(i)
+
+		var decisions []policy.Decision
+		var counts map[string]int
+		msg = msg.MapText(func(text string) string {
+			content, found, foundCounts := f.secrets.scan(text, fmt.Sprintf("message %d", i), enforce)
+			decisions = append(decisions, found...)
+			counts = detect.MergeCounts(counts, foundCounts)
+			return content
+		})
+		f.emitSecretDecisions(event, decisions, counts)
+		if decision, blocked := blockingDecision(decisions); blocked {
+			return nil, NewPolicyDeniedError(decision.Reason)
+		}
+
+		if len(msg.ToolCalls) > 0 {
+			toolCalls := make([]normalize.ToolCall, len(msg.ToolCalls))
+			for j, toolCall := range msg.ToolCalls {
+				args, decisions, counts := f.secrets.scan(toolCall.Function.Arguments, fmt.Sprintf("arguments of tool %q", toolCall.Function.Name), enforce)
+				toolCall.Function.Arguments = args
+				f.emitSecretDecisions(event.WithToolName(toolCall.Function.Name).WithToolCallID(toolCall.ID), decisions, counts)
+				if decision, blocked := blockingDecision(decisions); blocked {
+					return nil, NewPolicyDeniedError(decision.Reason)
+				}
+				toolCalls[j] = toolCall
+			}
+			msg.ToolCalls = toolCalls
+		}
+		messages[i] = msg
+	}
+	return messages, nil
+}
+
+// checkToolCallSecrets scans the arguments the model proposed for one tool
+// call. It reports a blocking decision, which the caller enforces like any
+// other tool denial, and whether the arguments need masking.
+func (f *Flow) checkToolCallSecrets(traceID, model string, toolCall normalize.ToolCall) (policy.Decision, bool, bool) {
+	if f.secrets == nil {
+		return policy.Decision{}, false, false
+	}
+
+	enforce := !f.policy.DryRun()
+	masked, decisions, counts := f.secrets.scan(toolCall.Function.Arguments, fmt.Sprintf("arguments of tool %q", toolCall.Function.Name), enforce)
+	f.emitSecretDecisions(
+		audit.NewEvent(traceID, audit.EventTypePolicyDecision).
+			WithProvider(f.provider.Name()).
+			WithModel(model).
+			WithToolName(toolCall.Function.Name).
+			WithToolCallID(toolCall.ID),
+		decisions,
+		counts,
+	)
+	decision, blocked := blockingDecision(decisions)
+	return decision, blocked, masked != toolCall.Function.Arguments
+}
+
+func (f *Flow) maskSecretArguments(edit *normalize.ResponseEdit) {
+	edit.Arguments = chainTransforms(edit.Arguments, func(args string) string {
+		masked, _, _ := f.secrets.scan(args, "", true)
+		return masked
+	})
+}
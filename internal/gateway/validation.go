@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/jsonschema"
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+const maxReportedViolations = 3
+
+// validateToolCall checks a proposed call against the tools declared in the
+// request: the name must be declared and the arguments must be JSON that
+// satisfies the declared parameters schema.
+func validateToolCall(declared []normalize.Tool, toolCall normalize.ToolCall) (policy.Decision, bool) {
+	name := toolCall.Function.Name
+
+	var tool *normalize.Tool
+	for i := range declared {
+		if declared[i].Function.Name == name {
+			tool = &declared[i]
+			break
+		}
+	}
+	if tool == nil {
+		return policy.NewDenyDecision("TOOL_UNDECLARED", fmt.Sprintf("tool %q was not declared in the request", name)), false
+	}
+
+	arguments := strings.TrimSpace(toolCall.Function.Arguments)
+	if arguments == "" {
+		arguments = "{}"
+	}
+	var args interface{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return policy.NewDenyDecision("TOOL_ARGS_INVALID_JSON", fmt.Sprintf("arguments of tool %q are not valid JSON", name)), false
+	}
+
+	if tool.Function.Parameters == nil {
+		return policy.Decision{}, true
+	}
+	// Round-trip the schema so it has the same shape as decoded JSON even
+	// when it was built in Go.
+	var schema interface{}
+	if data, err := json.Marshal(tool.Function.Parameters); err == nil {
+		_ = json.Unmarshal(data, &schema)
+	}
+
+	violations := jsonschema.Validate(schema, args)
+	if len(violations) == 0 {
+		return policy.Decision{}, true
+	}
+	messages := make([]string, 0, maxReportedViolations)
+	for i, violation := range violations {
+		if i == maxReportedViolations {
+			messages = append(messages, fmt.Sprintf("and %d more", len(violations)-i))
+			break
+		}
+		messages = append(messages, violation.String())
+	}
+	return policy.NewDenyDecision(
+		"TOOL_SCHEMA_VIOLATION",
+		fmt.Sprintf("arguments of tool %q do not match its schema: %s", name, strings.Join(messages, "; ")),
+	), false
+}
+
+// rejectInvalidToolCall applies the configured validation mode to a call
+// that failed validateToolCall.
+func (f *Flow) rejectInvalidToolCall(edit *normalize.ResponseEdit, index int, toolName string, decision policy.Decision) error {
+	if f.policy.ToolValidationMode() == config.ToolValidationErrorResult {
+		edit.DropToolCalls[index] = fmt.Sprintf("The call to tool %q was not executed: %s", toolName, decision.Reason)
+		return nil
+	}
+	return f.denyToolCall(edit, index, toolName, decision)
+}
@@ -0,0 +1,74 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return value
+}
+
+func TestValidate(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"path": {"type": "string", "minLength": 1, "pattern": "^/"},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 100},
+			"mode": {"enum": ["read", "write"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"target": {"anyOf": [{"type": "string"}, {"type": "null"}]}
+		},
+		"required": ["path"],
+		"additionalProperties": false
+	}`
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"valid", `{"path":"/tmp/a","limit":10,"mode":"read","tags":["x"],"target":null}`, nil},
+		{"missing required", `{"limit":1}`, []string{`$: missing required property "path"`}},
+		{"wrong type", `{"path":42}`, []string{"$.path: expected string, got number"}},
+		{"not integer", `{"path":"/a","limit":1.5}`, []string{"$.limit: expected integer, got number"}},
+		{"out of range", `{"path":"/a","limit":500}`, []string{"$.limit: must be <= 100"}},
+		{"enum", `{"path":"/a","mode":"delete"}`, []string{"$.mode: value is not one of the allowed values"}},
+		{"pattern", `{"path":"relative"}`, []string{`$.path: does not match pattern "^/"`}},
+		{"extra property", `{"path":"/a","force":true}`, []string{"$.force: additional property is not allowed"}},
+		{"array items", `{"path":"/a","tags":["x",1,"z"]}`, []string{"$.tags: expected at most 2 items", "$.tags[1]: expected string, got number"}},
+		{"anyOf", `{"path":"/a","target":3}`, []string{"$.target: value does not match any allowed schema"}},
+		{"not an object", `[]`, []string{"$: expected object, got array"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := Validate(decode(t, schema), decode(t, tt.value))
+			got := make([]string, 0, len(violations))
+			for _, violation := range violations {
+				got = append(got, violation.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_BooleanAndEmptySchemas(t *testing.T) {
+	if got := Validate(true, "anything"); len(got) != 0 {
+		t.Errorf("Validate(true) = %v", got)
+	}
+	if got := Validate(false, "anything"); len(got) != 1 {
+		t.Errorf("Validate(false) = %v", got)
+	}
+	if got := Validate(map[string]interface{}{}, 3.0); len(got) != 0 {
+		t.Errorf("Validate({}) = %v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package normalize
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ContentPartText       = "text"
+	ContentPartImageURL   = "image_url"
+	ContentPartInputAudio = "input_audio"
+	ContentPartFile       = "file"
+	ContentPartRefusal    = "refusal"
+)
+
+// ContentPart is one element of the array form of message content.
+type ContentPart struct {
+	Type       string      `json:"type"`
+	Text       string      `json:"text,omitempty"`
+	Refusal    string      `json:"refusal,omitempty"`
+	ImageURL   *ImageURL   `json:"image_url,omitempty"`
+	InputAudio *InputAudio `json:"input_audio,omitempty"`
+	File       *FilePart   `json:"file,omitempty"`
+}
+
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type InputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
+
+type FilePart struct {
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// DataURL splits a base64 data URL into its media type and payload. It
+// returns false for remote URLs and for data URLs that are not base64.
+func (u ImageURL) DataURL() (string, string, bool) {
+	rest, ok := strings.CutPrefix(u.URL, "data:")
+	if !ok {
+		return "", "", false
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return "", "", false
+	}
+	mediaType, ok := strings.CutSuffix(meta, ";base64")
+	if !ok {
+		return "", "", false
+	}
+	return mediaType, data, true
+}
+
+// IsRemote reports whether the image is fetched from a URL rather than
+// inlined as a data URL.
+func (u ImageURL) IsRemote() bool {
+	return !strings.HasPrefix(u.URL, "data:")
+}
+
+// DecodedSize returns the size in bytes of an inline image, computed from
+// the payload length without decoding it, or -1 for a remote image or a
+// payload whose length is not valid padded base64.
+func (u ImageURL) DecodedSize() int {
+	_, data, ok := u.DataURL()
+	if !ok || len(data)%4 != 0 {
+		return -1
+	}
+	padding := len(data) - len(strings.TrimRight(data, "="))
+	if padding > 2 {
+		return -1
+	}
+	return base64.StdEncoding.DecodedLen(len(data)) - padding
+}
+
+func (p ContentPart) validate() error {
+	switch p.Type {
+	case ContentPartText, ContentPartRefusal:
+	case ContentPartImageURL:
+		if p.ImageURL == nil || p.ImageURL.URL == "" {
+			return fmt.Errorf("image_url part requires a url")
+		}
+	case ContentPartInputAudio:
+		if p.InputAudio == nil || p.InputAudio.Data == "" {
+			return fmt.Errorf("input_audio part requires data")
+		}
+	case ContentPartFile:
+		if p.File == nil || (p.File.FileID == "" && p.File.FileData == "") {
+			return fmt.Errorf("file part requires file_id or file_data")
+		}
+	default:
+		return fmt.Errorf("unsupported content part type %q", p.Type)
+	}
+	return nil
+}
+
+var messageFields = jsonFieldNames(reflect.TypeOf(Message{}))
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	var data []byte
+	var err error
+	if m.Parts == nil {
+		data, err = json.Marshal(plain(m))
+	} else {
+		data, err = json.Marshal(struct {
+			plain
+			Content []ContentPart `json:"content"`
+		}{plain(m), m.Parts})
+	}
+	if err != nil || len(m.Extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range m.Extra {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON accepts content as a string, null or an array of parts.
+// Fields Message does not model are kept in Extra.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type plain Message
+	var raw struct {
+		plain
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = Message(raw.plain)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, value := range fields {
+		if messageFields[strings.ToLower(name)] {
+			continue
+		}
+		if m.Extra == nil {
+			m.Extra = make(map[string]json.RawMessage)
+		}
+		m.Extra[name] = bytes.Clone(value)
+	}
+
+	content := strings.TrimSpace(string(raw.Content))
+	switch {
+	case content == "" || content == "null":
+		return nil
+	case content[0] == '[':
+		var parts []ContentPart
+		if err := json.Unmarshal(raw.Content, &parts); err != nil {
+			return err
+		}
+		for i, part := range parts {
+			if err := part.validate(); err != nil {
+				return fmt.Errorf("content part %d: %w", i, err)
+			}
+		}
+		m.Parts = parts
+		return nil
+	default:
+		return json.Unmarshal(raw.Content, &m.Content)
+	}
+}
+
+// Text returns the text of the message. For the array form it joins the
+// text and refusal parts with newlines.
+func (m Message) Text() string {
+	if m.Parts == nil {
+		return m.Content
+	}
+	texts := make([]string, 0, len(m.Parts))
+	for _, part := range m.Parts {
+		switch part.Type {
+		case ContentPartText:
+			texts = append(texts, part.Text)
+		case ContentPartRefusal:
+			texts = append(texts, part.Refusal)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ContentChars returns the size of the message content in characters: the
+// text and refusal parts plus the encoded audio and file payloads. Images
+// are bounded by the image policy instead.
+func (m Message) ContentChars() int {
+	chars := utf8.RuneCountInString(m.Text())
+	for _, part := range m.Parts {
+		switch {
+		case part.InputAudio != nil:
+			chars += len(part.InputAudio.Data)
+		case part.File != nil:
+			chars += len(part.File.FileData)
+		}
+	}
+	return chars
+}
+
+// MapText returns a copy of the message with fn applied to the content, or
+// to every text and refusal part of the array form.
+func (m Message) MapText(fn func(string) string) Message {
+	if m.Parts == nil {
+		m.Content = fn(m.Content)
+		return m
+	}
+	parts := make([]ContentPart, len(m.Parts))
+	for i, part := range m.Parts {
+		switch part.Type {
+		case ContentPartText:
+			part.Text = fn(part.Text)
+		case ContentPartRefusal:
+			part.Refusal = fn(part.Refusal)
+		}
+		parts[i] = part
+	}
+	m.Parts = parts
+	return m
+}
+
+// WithText returns a copy of the message whose content is replaced by
+// text, dropping any content parts.
+func (m Message) WithText(text string) Message {
+	m.Content = text
+	m.Parts = nil
+	return m
+}
+
+// Images returns the image parts of the message.
+func (m Message) Images() []ImageURL {
+	var images []ImageURL
+	for _, part := range m.Parts {
+		if part.Type == ContentPartImageURL && part.ImageURL != nil {
+			images = append(images, *part.ImageURL)
+		}
+	}
+	return images
+}
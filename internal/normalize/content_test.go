@@ -0,0 +1,147 @@
+package normalize
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestMessage_ContentParts(t *testing.T) {
+	payload := `{"model":"gpt-4o","messages":[{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,aGVsbG8=","detail":"low"}}]},{"role":"assistant","content":null}]}`
+
+	req, err := DecodeOpenAIRequest(bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatalf("DecodeOpenAIRequest() error = %v", err)
+	}
+
+	msg := req.Messages[0]
+	if len(msg.Parts) != 2 || msg.Parts[1].ImageURL == nil || msg.Parts[1].ImageURL.Detail != "low" {
+		t.Fatalf("Parts = %#v, want text and image parts", msg.Parts)
+	}
+	if msg.Text() != "what is this?" {
+		t.Errorf("Text() = %q, want %q", msg.Text(), "what is this?")
+	}
+	if req.Messages[1].Parts != nil || req.Messages[1].Content != "" {
+		t.Errorf("null content = %#v, want empty", req.Messages[1])
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,aGVsbG8=","detail":"low"}}]}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	data, err = json.Marshal(Message{Role: "user", Content: "hi"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"role":"user","content":"hi"}` {
+		t.Errorf("Marshal() = %s, want string content", data)
+	}
+}
+
+func TestMessage_ExtraFields(t *testing.T) {
+	payload := `{"role":"assistant","name":"planner","content":"hi","refusal":null,"audio":{"id":"audio-1"}}`
+
+	var msg Message
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if msg.Name != "planner" {
+		t.Errorf("Name = %q, want planner", msg.Name)
+	}
+	if len(msg.Extra) != 2 || string(msg.Extra["audio"]) != `{"id":"audio-1"}` || string(msg.Extra["refusal"]) != "null" {
+		t.Errorf("Extra = %v, want audio and refusal", msg.Extra)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"audio":{"id":"audio-1"},"content":"hi","name":"planner","refusal":null,"role":"assistant"}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestMessage_InvalidContentParts(t *testing.T) {
+	tests := []string{
+		`{"role":"user","content":[{"type":"video","video":{}}]}`,
+		`{"role":"user","content":[{"type":"image_url"}]}`,
+		`{"role":"user","content":[{"type":"file","file":{}}]}`,
+		`{"role":"user","content":42}`,
+	}
+
+	for _, payload := range tests {
+		var msg Message
+		if err := json.Unmarshal([]byte(payload), &msg); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", payload)
+		}
+	}
+}
+
+func TestMessage_MapText(t *testing.T) {
+	msg := Message{Role: "assistant", Parts: []ContentPart{
+		{Type: ContentPartText, Text: "a"},
+		{Type: ContentPartImageURL, ImageURL: &ImageURL{URL: "https://example.com/a.png"}},
+		{Type: ContentPartRefusal, Refusal: "b"},
+	}}
+
+	mapped := msg.MapText(func(text string) string { return text + "!" })
+	if mapped.Text() != "a!\nb!" {
+		t.Errorf("Text() = %q, want %q", mapped.Text(), "a!\nb!")
+	}
+	if mapped.Parts[2].Refusal != "b!" {
+		t.Errorf("refusal part = %q, want it mapped", mapped.Parts[2].Refusal)
+	}
+	if msg.Parts[0].Text != "a" {
+		t.Error("MapText() modified the original parts")
+	}
+	if len(mapped.Images()) != 1 {
+		t.Errorf("Images() = %#v, want one image", mapped.Images())
+	}
+}
+
+func TestMessage_ContentChars(t *testing.T) {
+	msg := Message{Role: "user", Parts: []ContentPart{
+		{Type: ContentPartText, Text: "héllo"},
+		{Type: ContentPartInputAudio, InputAudio: &InputAudio{Data: "AAAA", Format: "wav"}},
+		{Type: ContentPartFile, File: &FilePart{FileData: "BBBBBBBB"}},
+		{Type: ContentPartImageURL, ImageURL: &ImageURL{URL: "data:image/png;base64,aGVsbG8="}},
+	}}
+	if got := msg.ContentChars(); got != 5+4+8 {
+		t.Errorf("ContentChars() = %d, want %d", got, 5+4+8)
+	}
+}
+
+func TestImageURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		remote   bool
+		size     int
+		wantType string
+	}{
+		{url: "data:image/png;base64,aGVsbG8=", size: 5, wantType: "image/png"},
+		{url: "data:image/png;base64,aGVsbG8h", size: 6, wantType: "image/png"},
+		{url: "data:image/png;base64,aGVsbA==", size: 4, wantType: "image/png"},
+		{url: "data:image/png;base64,!!", size: -1, wantType: "image/png"},
+		{url: "data:text/plain,hello", size: -1},
+		{url: "https://example.com/a.png", remote: true, size: -1},
+	}
+
+	for _, tt := range tests {
+		image := ImageURL{URL: tt.url}
+		if image.IsRemote() != tt.remote {
+			t.Errorf("IsRemote(%q) = %v, want %v", tt.url, image.IsRemote(), tt.remote)
+		}
+		if image.DecodedSize() != tt.size {
+			t.Errorf("DecodedSize(%q) = %d, want %d", tt.url, image.DecodedSize(), tt.size)
+		}
+		if mediaType, _, _ := image.DataURL(); mediaType != tt.wantType {
+			t.Errorf("DataURL(%q) media type = %q, want %q", tt.url, mediaType, tt.wantType)
+		}
+	}
+}
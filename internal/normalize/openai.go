@@ -1,9 +1,12 @@
 package normalize
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
+	"reflect"
+	"strings"
 )
 
 type OpenAIRequest struct {
@@ -11,22 +14,67 @@ type OpenAIRequest struct {
 	Messages []Message `json:"messages"`
 	Stream   bool      `json:"stream,omitempty"`
 	Tools    []Tool    `json:"tools,omitempty"`
+	RequestOptions
 }
 
+var openAIRequestFields = jsonFieldNames(reflect.TypeOf(OpenAIRequest{}))
+
+// DecodeOpenAIRequest decodes a Chat Completions request. Top-level fields
+// that OpenAIRequest does not model are returned in Extra so the caller
+// can decide whether to forward them.
 func DecodeOpenAIRequest(r io.Reader) (NormalizedRequest, error) {
 	dec := json.NewDecoder(r)
-	dec.DisallowUnknownFields()
-	var req OpenAIRequest
-	if err := dec.Decode(&req); err != nil {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
 		return NormalizedRequest{}, err
 	}
 	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
 		return NormalizedRequest{}, errors.New("invalid trailing data")
 	}
+
+	var req OpenAIRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return NormalizedRequest{}, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return NormalizedRequest{}, err
+	}
+
+	var extra map[string]json.RawMessage
+	for name, value := range fields {
+		if openAIRequestFields[strings.ToLower(name)] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[name] = bytes.Clone(value)
+	}
+
 	return NormalizedRequest{
-		Model:    req.Model,
-		Messages: req.Messages,
-		Stream:   req.Stream,
-		Tools:    req.Tools,
+		Model:          req.Model,
+		Messages:       req.Messages,
+		Stream:         req.Stream,
+		Tools:          req.Tools,
+		RequestOptions: req.RequestOptions,
+		Extra:          extra,
 	}, nil
 }
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			for name := range jsonFieldNames(field.Type) {
+				names[name] = true
+			}
+			continue
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -28,12 +28,114 @@ func TestDecodeOpenAIRequest_Valid(t *testing.T) {
 	}
 }
 
-func TestDecodeOpenAIRequest_UnknownField(t *testing.T) {
-	payload := `{"model":"gpt-4o","messages":[],"unknown":true}`
+func TestDecodeOpenAIRequest_SamplingFields(t *testing.T) {
+	payload := `{"model":"gpt-4o","messages":[],"max_tokens":512,"temperature":0.2,"top_p":0.9,"n":2,"response_format":{"type":"json_object"}}`
 
-	_, err := DecodeOpenAIRequest(bytes.NewBufferString(payload))
-	if err == nil {
-		t.Fatal("DecodeOpenAIRequest() expected error for unknown field")
+	req, err := DecodeOpenAIRequest(bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatalf("DecodeOpenAIRequest() error = %v", err)
+	}
+
+	if req.MaxTokens == nil || *req.MaxTokens != 512 {
+		t.Errorf("MaxTokens = %v, want 512", req.MaxTokens)
+	}
+	if req.Temperature == nil || *req.Temperature != 0.2 {
+		t.Errorf("Temperature = %v, want 0.2", req.Temperature)
+	}
+	if req.TopP == nil || *req.TopP != 0.9 {
+		t.Errorf("TopP = %v, want 0.9", req.TopP)
+	}
+	if req.N == nil || *req.N != 2 {
+		t.Errorf("N = %v, want 2", req.N)
+	}
+	if req.ResponseFormat == nil || req.ResponseFormat.Type != "json_object" {
+		t.Errorf("ResponseFormat = %#v, want json_object", req.ResponseFormat)
+	}
+}
+
+func TestDecodeOpenAIRequest_RequestOptions(t *testing.T) {
+	payload := `{"model":"gpt-4o","messages":[],"tool_choice":{"type":"function","function":{"name":"search_web"}},"parallel_tool_calls":false,"stop":"END","seed":7,"user":"u-1","stream_options":{"include_usage":true}}`
+
+	req, err := DecodeOpenAIRequest(bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatalf("DecodeOpenAIRequest() error = %v", err)
+	}
+
+	if req.ToolChoice == nil || req.ToolChoice.Function != "search_web" {
+		t.Errorf("ToolChoice = %#v, want function search_web", req.ToolChoice)
+	}
+	if req.ParallelToolCalls == nil || *req.ParallelToolCalls {
+		t.Errorf("ParallelToolCalls = %v, want false", req.ParallelToolCalls)
+	}
+	if len(req.Stop) != 1 || req.Stop[0] != "END" {
+		t.Errorf("Stop = %v, want [END]", req.Stop)
+	}
+	if req.Seed == nil || *req.Seed != 7 {
+		t.Errorf("Seed = %v, want 7", req.Seed)
+	}
+	if req.User != "u-1" {
+		t.Errorf("User = %q, want u-1", req.User)
+	}
+	if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+		t.Errorf("StreamOptions = %#v, want include_usage", req.StreamOptions)
+	}
+	if len(req.Extra) != 0 {
+		t.Errorf("Extra = %v, want none", req.Extra)
+	}
+}
+
+func TestDecodeOpenAIRequest_ToolChoiceAndStop(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		choice  ToolChoice
+		stop    []string
+		wantErr bool
+	}{
+		{name: "mode", payload: `{"tool_choice":"required","stop":["a","b"]}`, choice: ToolChoice{Mode: ToolChoiceRequired}, stop: []string{"a", "b"}},
+		{name: "named function", payload: `{"tool_choice":{"type":"function","function":{"name":"f"}}}`, choice: ToolChoice{Function: "f"}},
+		{name: "named without name", payload: `{"tool_choice":{"type":"function","function":{}}}`, wantErr: true},
+		{name: "bad stop", payload: `{"stop":3}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := DecodeOpenAIRequest(bytes.NewBufferString(tt.payload))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("DecodeOpenAIRequest() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeOpenAIRequest() error = %v", err)
+			}
+			if req.ToolChoice == nil || *req.ToolChoice != tt.choice {
+				t.Errorf("ToolChoice = %#v, want %#v", req.ToolChoice, tt.choice)
+			}
+			if strings.Join(req.Stop, ",") != strings.Join(tt.stop, ",") {
+				t.Errorf("Stop = %v, want %v", req.Stop, tt.stop)
+			}
+		})
+	}
+}
+
+func TestDecodeOpenAIRequest_ExtraFields(t *testing.T) {
+	payload := `{"model":"gpt-4o","messages":[],"prediction":{"type":"content"},"metadata":{"k":"v"}}`
+
+	req, err := DecodeOpenAIRequest(bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatalf("DecodeOpenAIRequest() error = %v", err)
+	}
+
+	if len(req.Extra) != 2 {
+		t.Fatalf("Extra = %v, want prediction and metadata", req.Extra)
+	}
+	if string(req.Extra["prediction"]) != `{"type":"content"}` {
+		t.Errorf("Extra[prediction] = %s", req.Extra["prediction"])
+	}
+	if _, ok := req.Extra["metadata"]; !ok {
+		t.Error("Extra[metadata] missing")
 	}
 }
 
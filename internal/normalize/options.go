@@ -0,0 +1,108 @@
+package normalize
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// RequestOptions are the Chat Completions generation options. They are
+// embedded in both the wire request and NormalizedRequest.
+type RequestOptions struct {
+	ToolChoice          *ToolChoice        `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool              `json:"parallel_tool_calls,omitempty"`
+	MaxTokens           *int               `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int               `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64           `json:"temperature,omitempty"`
+	TopP                *float64           `json:"top_p,omitempty"`
+	N                   *int               `json:"n,omitempty"`
+	Stop                Stop               `json:"stop,omitempty"`
+	Seed                *int64             `json:"seed,omitempty"`
+	FrequencyPenalty    *float64           `json:"frequency_penalty,omitempty"`
+	PresencePenalty     *float64           `json:"presence_penalty,omitempty"`
+	LogitBias           map[string]float64 `json:"logit_bias,omitempty"`
+	Logprobs            *bool              `json:"logprobs,omitempty"`
+	TopLogprobs         *int               `json:"top_logprobs,omitempty"`
+	ResponseFormat      *ResponseFormat    `json:"response_format,omitempty"`
+	StreamOptions       *StreamOptions     `json:"stream_options,omitempty"`
+	User                string             `json:"user,omitempty"`
+	ReasoningEffort     string             `json:"reasoning_effort,omitempty"`
+	ServiceTier         string             `json:"service_tier,omitempty"`
+	Store               *bool              `json:"store,omitempty"`
+}
+
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema interface{} `json:"json_schema,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+const (
+	ToolChoiceNone     = "none"
+	ToolChoiceAuto     = "auto"
+	ToolChoiceRequired = "required"
+)
+
+// ToolChoice is either a mode ("none", "auto", "required") or, when
+// Function is set, a specific function the model must call.
+type ToolChoice struct {
+	Mode     string
+	Function string
+}
+
+func (c ToolChoice) MarshalJSON() ([]byte, error) {
+	if c.Function == "" {
+		return json.Marshal(c.Mode)
+	}
+	return json.Marshal(map[string]interface{}{
+		"type":     "function",
+		"function": map[string]string{"name": c.Function},
+	})
+}
+
+func (c *ToolChoice) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*c = ToolChoice{Mode: mode}
+		return nil
+	}
+
+	var named struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+	if named.Type != "function" || named.Function.Name == "" {
+		return errors.New("tool_choice must be a mode or a named function")
+	}
+	*c = ToolChoice{Function: named.Function.Name}
+	return nil
+}
+
+// Stop accepts a single stop sequence or a list of them.
+type Stop []string
+
+func (s *Stop) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*s = nil
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = Stop{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
@@ -1,10 +1,22 @@
 package normalize
 
+import (
+	"encoding/json"
+	"net/http"
+)
+
 type Message struct {
-	Role       string     `json:"role"`
-	Content    string     `json:"content"`
-	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
-	ToolCallID string     `json:"tool_call_id,omitempty"`
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Parts holds the content when the client sent it in array form. When
+	// set, it is used instead of Content.
+	Parts      []ContentPart `json:"-"`
+	Name       string        `json:"name,omitempty"`
+	ToolCalls  []ToolCall    `json:"tool_calls,omitempty"`
+	ToolCallID string        `json:"tool_call_id,omitempty"`
+	// Extra holds message fields the gateway does not model, keyed by
+	// field name. They are written back when the message is marshaled.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type ToolCall struct {
@@ -30,19 +42,45 @@ type ToolFunction struct {
 }
 
 type NormalizedRequest struct {
-	Model    string            `json:"model"`
-	Messages []Message         `json:"messages"`
-	Stream   bool              `json:"stream"`
-	Tools    []Tool            `json:"tools,omitempty"`
-	Metadata map[string]string `json:"-"`
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Tools    []Tool    `json:"tools,omitempty"`
+	RequestOptions
+	// Extra holds top-level request fields the gateway does not model,
+	// keyed by field name.
+	Extra    map[string]json.RawMessage `json:"-"`
+	Metadata map[string]string          `json:"-"`
+	Headers  http.Header                `json:"-"`
 }
 
 type NormalizedResponse struct {
-	ID        string     `json:"id"`
-	Model     string     `json:"model"`
-	Content   string     `json:"content"`
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
-	RawBody   []byte     `json:"-"`
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Content string `json:"content"`
+	// Contents holds the assistant text of every choice in order; Content is
+	// the first non-empty one.
+	Contents     []string   `json:"contents,omitempty"`
+	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        *Usage     `json:"usage,omitempty"`
+	RawBody      []byte     `json:"-"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (r *NormalizedRequest) ToolCallHistory() []string {
+	names := make([]string, 0)
+	for _, msg := range r.Messages {
+		for _, tc := range msg.ToolCalls {
+			names = append(names, tc.Function.Name)
+		}
+	}
+	return names
 }
 
 func (r *NormalizedResponse) ExtractToolNames() []string {
@@ -52,3 +90,39 @@ func (r *NormalizedResponse) ExtractToolNames() []string {
 	}
 	return names
 }
+
+type ResponseEdit struct {
+	// DropToolCalls is keyed by position in NormalizedResponse.ToolCalls. A
+	// non-empty value is appended to the assistant content of that choice.
+	DropToolCalls map[int]string
+	// Content, when set, rewrites the assistant text of every choice.
+	Content func(string) string
+	// ReplaceArguments is keyed like DropToolCalls and holds new JSON
+	// arguments for that tool call.
+	ReplaceArguments map[int]string
+	// Arguments, when set, rewrites the JSON arguments of every tool call
+	// that is kept, after any replacement.
+	Arguments func(string) string
+}
+
+func (e ResponseEdit) IsEmpty() bool {
+	return len(e.DropToolCalls) == 0 && len(e.ReplaceArguments) == 0 && e.Content == nil && e.Arguments == nil
+}
+
+// ArgumentsRewrite returns the rewrite for the tool call at index, or nil
+// when its arguments are left alone.
+func (e ResponseEdit) ArgumentsRewrite(index int) func(string) string {
+	replacement, replaced := e.ReplaceArguments[index]
+	if !replaced && e.Arguments == nil {
+		return nil
+	}
+	return func(args string) string {
+		if replaced {
+			args = replacement
+		}
+		if e.Arguments != nil {
+			args = e.Arguments(args)
+		}
+		return args
+	}
+}
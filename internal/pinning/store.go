@@ -0,0 +1,207 @@
+package pinning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alereyleyva/agent-guard/internal/normalize"
+)
+
+const (
+	StatusNew     = "new"
+	StatusMatch   = "match"
+	StatusDrifted = "drifted"
+)
+
+// Pin is one entry of a pin file. Description and Parameters are only there
+// for reviewers; the hash is what gets compared.
+type Pin struct {
+	Name        string      `json:"name" yaml:"name"`
+	Hash        string      `json:"hash" yaml:"hash"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	PinnedAt    time.Time   `json:"pinned_at,omitempty" yaml:"pinned_at,omitempty"`
+	LastSeen    time.Time   `json:"last_seen,omitempty" yaml:"last_seen,omitempty"`
+}
+
+type PinFile struct {
+	Tools []Pin `json:"tools" yaml:"tools"`
+}
+
+type Check struct {
+	Status     string
+	PinnedHash string
+	Hash       string
+	// Pinned reports that a new definition was pinned by this check.
+	Pinned bool
+}
+
+// Hash is the canonical hash of a tool definition. encoding/json sorts map
+// keys, so equivalent schemas hash the same regardless of key order.
+func Hash(tool normalize.ToolFunction) string {
+	data, _ := json.Marshal(struct {
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		Parameters  interface{} `json:"parameters"`
+	}{tool.Name, tool.Description, tool.Parameters})
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Tool definitions come from client requests, so the catalog and the pins
+// learned on first use are bounded. A full catalog forgets its least recently
+// seen definition; learned pins are never evicted, since that would let a
+// client flush a pin and re-pin a changed definition, so a full store stops
+// learning instead.
+const (
+	maxObserved    = 4096
+	maxLearnedPins = 10000
+)
+
+// Store holds the pins loaded from a pin file, which apply to every caller,
+// and the pins learned on first use, which are scoped to the caller that
+// declared the tool so one client cannot pin definitions for another.
+// Requests without a caller identity share one anonymous scope.
+type Store struct {
+	mu       sync.Mutex
+	pins     map[string]Pin
+	learned  map[pinKey]Pin
+	observed map[string]Pin
+	now      func() time.Time
+}
+
+type pinKey struct {
+	caller string
+	name   string
+}
+
+func NewStore() *Store {
+	return &Store{
+		pins:     make(map[string]Pin),
+		learned:  make(map[pinKey]Pin),
+		observed: make(map[string]Pin),
+		now:      time.Now,
+	}
+}
+
+func LoadFile(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading pin file: %w", err)
+	}
+	var file PinFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing pin file: %w", err)
+	}
+
+	store := NewStore()
+	for _, pin := range file.Tools {
+		if pin.Name == "" || pin.Hash == "" {
+			return nil, fmt.Errorf("parsing pin file: every tool needs a name and hash")
+		}
+		store.pins[pin.Name] = pin
+	}
+	return store, nil
+}
+
+// Observe records the definition in the catalog and compares it with the
+// caller's pin for its name, falling back to the pin file. pinNew pins
+// definitions that have no pin yet for the caller, or for the anonymous
+// scope when caller is empty.
+func (s *Store) Observe(caller string, tool normalize.ToolFunction, pinNew bool) Check {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	hash := Hash(tool)
+	observed := Pin{
+		Name:        tool.Name,
+		Hash:        hash,
+		Description: tool.Description,
+		Parameters:  tool.Parameters,
+		LastSeen:    now,
+	}
+	s.observe(observed)
+
+	pin, ok := s.pin(caller, tool.Name)
+	switch {
+	case !ok:
+		check := Check{Status: StatusNew, Hash: hash}
+		if pinNew && len(s.learned) < maxLearnedPins {
+			observed.PinnedAt = now
+			s.learned[pinKey{caller, tool.Name}] = observed
+			check.Pinned = true
+		}
+		return check
+	case pin.Hash == hash:
+		return Check{Status: StatusMatch, PinnedHash: pin.Hash, Hash: hash}
+	default:
+		return Check{Status: StatusDrifted, PinnedHash: pin.Hash, Hash: hash}
+	}
+}
+
+// Repin replaces the caller's pin for the tool and reports whether it did.
+// It does not touch the pin file entries, which only change by reloading the
+// file.
+func (s *Store) Repin(caller string, tool normalize.ToolFunction) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pinKey{caller, tool.Name}
+	if _, ok := s.learned[key]; !ok && len(s.learned) >= maxLearnedPins {
+		return false
+	}
+	now := s.now().UTC()
+	s.learned[key] = Pin{
+		Name:        tool.Name,
+		Hash:        Hash(tool),
+		Description: tool.Description,
+		Parameters:  tool.Parameters,
+		PinnedAt:    now,
+		LastSeen:    now,
+	}
+	return true
+}
+
+func (s *Store) pin(caller, name string) (Pin, bool) {
+	if pin, ok := s.learned[pinKey{caller, name}]; ok {
+		return pin, true
+	}
+	pin, ok := s.pins[name]
+	return pin, ok
+}
+
+func (s *Store) observe(pin Pin) {
+	if _, ok := s.observed[pin.Name]; !ok && len(s.observed) >= maxObserved {
+		oldest := ""
+		for name, seen := range s.observed {
+			if oldest == "" || seen.LastSeen.Before(s.observed[oldest].LastSeen) {
+				oldest = name
+			}
+		}
+		delete(s.observed, oldest)
+	}
+	s.observed[pin.Name] = pin
+}
+
+// Catalog returns every observed definition sorted by name, in pin file
+// format so it can be reviewed and loaded back as pins.
+func (s *Store) Catalog() PinFile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tools := make([]Pin, 0, len(s.observed))
+	for _, pin := range s.observed {
+		tools = append(tools, pin)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return PinFile{Tools: tools}
+}
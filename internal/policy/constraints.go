@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+type toolConstraint struct {
+	config.ToolConstraint
+	regex *regexp.Regexp
+	err   error
+}
+
+func compileConstraints(constraints []config.ToolConstraint) []toolConstraint {
+	compiled := make([]toolConstraint, 0, len(constraints))
+	for _, constraint := range constraints {
+		c := toolConstraint{ToolConstraint: constraint}
+		if constraint.Regex != "" {
+			c.regex, c.err = regexp.Compile(constraint.Regex)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+func (e *Engine) applyConstraints(in Input, decision Decision) Decision {
+	var args interface{}
+	parsed := false
+
+	for _, constraint := range e.constraints {
+		if !matchesPattern(in.Tool, constraint.Tool) {
+			continue
+		}
+
+		reason := ""
+		if !parsed {
+			args = map[string]interface{}{}
+			if strings.TrimSpace(in.Arguments) != "" {
+				if err := json.Unmarshal([]byte(in.Arguments), &args); err != nil {
+					args = nil
+				}
+			}
+			parsed = true
+		}
+		if args == nil {
+			reason = "arguments are not valid JSON"
+		} else {
+			reason = constraint.violation(args)
+		}
+		if reason == "" {
+			continue
+		}
+
+		denied := constraintDeny(in.Tool, constraint, reason)
+		if constraint.Mode == config.RuleModeMonitor {
+			denied.Shadow = true
+			decision.Monitored = append(decision.Monitored, denied)
+			continue
+		}
+		denied.Monitored = decision.Monitored
+		return denied
+	}
+
+	return decision
+}
+
+func constraintDeny(toolName string, constraint toolConstraint, reason string) Decision {
+	decision := NewDenyDecision(
+		"TOOL_CONSTRAINT",
+		fmt.Sprintf("tool %q violates constraint %q: %s", toolName, constraint.ID, reason),
+	)
+	decision.Constraint = constraint.ID
+	return decision
+}
+
+func (c toolConstraint) violation(args interface{}) string {
+	if c.err != nil {
+		return fmt.Sprintf("invalid regex: %v", c.err)
+	}
+
+	values := selectFields(args, c.Field)
+	if len(values) == 0 {
+		return fmt.Sprintf("field %q is missing", c.Field)
+	}
+
+	for _, value := range values {
+		if reason := c.check(value); reason != "" {
+			return fmt.Sprintf("field %q %s", c.Field, reason)
+		}
+	}
+	return ""
+}
+
+func (c toolConstraint) check(value interface{}) string {
+	if c.Min != nil || c.Max != nil {
+		number, ok := value.(float64)
+		if !ok {
+			return "must be a number"
+		}
+		if c.Min != nil && number < *c.Min {
+			return fmt.Sprintf("must be >= %v", *c.Min)
+		}
+		if c.Max != nil && number > *c.Max {
+			return fmt.Sprintf("must be <= %v", *c.Max)
+		}
+	}
+
+	if c.regex == nil && c.Prefix == "" && c.PathPrefix == "" && len(c.Enum) == 0 {
+		return ""
+	}
+
+	text, ok := stringValue(value)
+	if !ok {
+		return "must be a scalar value"
+	}
+	if c.regex != nil && !c.regex.MatchString(text) {
+		return fmt.Sprintf("must match %q", c.Regex)
+	}
+	if c.Prefix != "" && !strings.HasPrefix(text, c.Prefix) {
+		return fmt.Sprintf("must start with %q", c.Prefix)
+	}
+	if c.PathPrefix != "" && !isUnderPath(text, c.PathPrefix) {
+		return fmt.Sprintf("must be a path under %q", c.PathPrefix)
+	}
+	if len(c.Enum) > 0 && !containsString(c.Enum, text) {
+		return fmt.Sprintf("must be one of %v", c.Enum)
+	}
+	return ""
+}
+
+func isUnderPath(value, root string) bool {
+	if !strings.HasPrefix(value, "/") {
+		return false
+	}
+	root = path.Clean(root)
+	cleaned := path.Clean(value)
+	return cleaned == root || strings.HasPrefix(cleaned, strings.TrimSuffix(root, "/")+"/")
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestEvaluateToolCall_Constraints(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Tools: config.ToolPolicy{
+			Allow: []string{"*"},
+			Constraints: []config.ToolConstraint{
+				{ID: "workspace-only", Tool: "read_file", Field: "path", PathPrefix: "/workspace"},
+				{ID: "allowed-hosts", Tool: "http_get", Field: "url", Regex: `^https://(api|docs)\.example\.com/`},
+				{ID: "small-deletes", Tool: "delete_records", Field: "limit", Min: floatPtr(1), Max: floatPtr(10)},
+				{ID: "known-regions", Tool: "deploy", Field: "targets.*.region", Enum: []string{"eu-west-1", "us-east-1"}},
+				{ID: "tmp-prefix", Tool: "write_file", Field: "path", Prefix: "/tmp/"},
+			},
+		},
+	})
+
+	tests := []struct {
+		name           string
+		tool           string
+		arguments      string
+		wantAllow      bool
+		wantConstraint string
+	}{
+		{"path inside workspace", "read_file", `{"path":"/workspace/src/main.go"}`, true, ""},
+		{"path traversal", "read_file", `{"path":"/workspace/../etc/passwd"}`, false, "workspace-only"},
+		{"missing field", "read_file", `{}`, false, "workspace-only"},
+		{"allowed host", "http_get", `{"url":"https://api.example.com/v1"}`, true, ""},
+		{"other host", "http_get", `{"url":"https://evil.test/"}`, false, "allowed-hosts"},
+		{"limit in range", "delete_records", `{"limit":10}`, true, ""},
+		{"limit too high", "delete_records", `{"limit":500}`, false, "small-deletes"},
+		{"limit not a number", "delete_records", `{"limit":"5"}`, false, "small-deletes"},
+		{"all regions known", "deploy", `{"targets":[{"region":"eu-west-1"},{"region":"us-east-1"}]}`, true, ""},
+		{"unknown region", "deploy", `{"targets":[{"region":"eu-west-1"},{"region":"ap-south-1"}]}`, false, "known-regions"},
+		{"prefix match", "write_file", `{"path":"/tmp/out.txt"}`, true, ""},
+		{"invalid JSON", "write_file", `{"path":`, false, "tmp-prefix"},
+		{"unconstrained tool", "search_web", `{"q":"hi"}`, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateToolCall(Input{Tool: tt.tool, Arguments: tt.arguments})
+			if decision.IsAllowed() != tt.wantAllow {
+				t.Errorf("EvaluateToolCall(%q, %s) = %v (%s), want allowed=%v", tt.tool, tt.arguments, decision.Action, decision.Reason, tt.wantAllow)
+			}
+			if decision.Constraint != tt.wantConstraint {
+				t.Errorf("Constraint = %q, want %q", decision.Constraint, tt.wantConstraint)
+			}
+		})
+	}
+}
+
+func TestEvaluateToolCall_NameDenyTakesPrecedence(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Tools: config.ToolPolicy{
+			Allow:       []string{"*"},
+			Deny:        []string{"read_file"},
+			Constraints: []config.ToolConstraint{{ID: "workspace-only", Tool: "read_file", Field: "path", PathPrefix: "/workspace"}},
+		},
+	})
+
+	decision := engine.EvaluateToolCall(Input{Tool: "read_file", Arguments: `{"path":"/workspace/a"}`})
+	if decision.IsAllowed() || decision.RuleID != "TOOL_DENY" {
+		t.Errorf("EvaluateToolCall() = %#v, want TOOL_DENY", decision)
+	}
+}
+
+func TestEvaluateToolCall_ConstraintsBeforeApproval(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "approve-reads", Action: config.RuleActionRequireApproval, Match: config.RuleMatch{Tools: []string{"read_file"}}},
+		},
+		Tools: config.ToolPolicy{
+			Allow:       []string{"*"},
+			Constraints: []config.ToolConstraint{{ID: "workspace-only", Tool: "read_file", Field: "path", PathPrefix: "/workspace"}},
+		},
+	})
+
+	decision := engine.EvaluateToolCall(Input{Tool: "read_file", Arguments: `{"path":"/etc/passwd"}`})
+	if decision.Action != ActionDeny || decision.Constraint != "workspace-only" {
+		t.Errorf("EvaluateToolCall() = %#v, want a workspace-only constraint deny", decision)
+	}
+
+	decision = engine.EvaluateToolCall(Input{Tool: "read_file", Arguments: `{"path":"/workspace/a"}`})
+	if !decision.RequiresApproval() {
+		t.Errorf("EvaluateToolCall() = %#v, want require_approval", decision)
+	}
+}
@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+const defaultContentMask = "[REDACTED]"
+
+type contentRule struct {
+	config.ContentRule
+	patterns []*regexp.Regexp
+}
+
+type ContentResult struct {
+	Decisions []Decision
+	// Content is the input with every enforced mask rule applied.
+	Content string
+}
+
+func (r ContentResult) Blocked() (Decision, bool) {
+	for _, decision := range r.Decisions {
+		if decision.Action == ActionDeny {
+			return decision, true
+		}
+	}
+	return Decision{}, false
+}
+
+func compileContentRules(rules []config.ContentRule) []contentRule {
+	compiled := make([]contentRule, 0, len(rules))
+	for _, r := range rules {
+		c := contentRule{ContentRule: r}
+		flags := ""
+		if r.CaseInsensitive {
+			flags = "(?i)"
+		}
+		for _, keyword := range r.Keywords {
+			c.patterns = append(c.patterns, regexp.MustCompile(flags+regexp.QuoteMeta(keyword)))
+		}
+		for _, expr := range r.Regex {
+			if re, err := regexp.Compile(flags + expr); err == nil {
+				c.patterns = append(c.patterns, re)
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+// EvaluateContent runs the content rules for target ("request" or
+// "response") over one message. Every matching rule yields a decision.
+func (e *Engine) EvaluateContent(target, role, content string) ContentResult {
+	result := ContentResult{Content: content}
+	for _, r := range e.content {
+		if !r.appliesTo(target, role) || !r.matches(result.Content) {
+			continue
+		}
+
+		reason := r.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("%s content matched rule %q", target, r.ID)
+		}
+		switch r.Action {
+		case config.ContentActionBlock:
+			result.Decisions = append(result.Decisions, e.finalize(NewDenyDecision(r.ID, reason)))
+		case config.ContentActionMask:
+			decision := Decision{Action: ActionMask, RuleID: r.ID, Reason: reason}
+			if e.dryRun {
+				decision.Shadow = true
+			} else {
+				result.Content = r.mask(result.Content)
+			}
+			result.Decisions = append(result.Decisions, decision)
+		default:
+			result.Decisions = append(result.Decisions, Decision{Action: ActionFlag, RuleID: r.ID, Reason: reason})
+		}
+	}
+	return result
+}
+
+// ChecksResponseContent reports whether any content rule applies to the
+// assistant text of responses.
+func (e *Engine) ChecksResponseContent() bool {
+	for _, r := range e.content {
+		if r.appliesTo(config.ContentTargetResponse, "assistant") {
+			return true
+		}
+	}
+	return false
+}
+
+func (r contentRule) appliesTo(target, role string) bool {
+	ruleTarget := r.Target
+	if ruleTarget == "" {
+		ruleTarget = config.ContentTargetRequest
+	}
+	if ruleTarget != config.ContentTargetBoth && ruleTarget != target {
+		return false
+	}
+	if len(r.Roles) == 0 {
+		return true
+	}
+	for _, allowed := range r.Roles {
+		if strings.EqualFold(allowed, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r contentRule) matches(content string) bool {
+	for _, re := range r.patterns {
+		if re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r contentRule) mask(content string) string {
+	replacement := r.Mask
+	if replacement == "" {
+		replacement = defaultContentMask
+	}
+	for _, re := range r.patterns {
+		content = re.ReplaceAllLiteralString(content, replacement)
+	}
+	return content
+}
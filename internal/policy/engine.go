@@ -2,25 +2,162 @@ package policy
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/alereyleyva/agent-guard/internal/config"
 )
 
+const defaultIdentityHeader = "X-AgentGuard-Client"
+
 type Engine struct {
-	modelPolicy config.ModelPolicy
-	toolPolicy  config.ToolPolicy
+	dryRun         bool
+	identityHeader string
+	sessionHeader  string
+	sessions       *sessionTracker
+	rules          []rule
+	modelPolicy    config.ModelPolicy
+	toolPolicy     config.ToolPolicy
+	constraints    []toolConstraint
+	content        []contentRule
+	catalog        config.ToolCatalog
+	modelParams    []config.ModelParamRule
+	requestFields  config.RequestFields
+	images         config.ImagePolicy
+}
+
+type Input struct {
+	Model string
+	// RequestedModel is the alias the client asked for when Model was
+	// resolved from one; policies match either name.
+	RequestedModel string
+	Provider       string
+	Tool           string
+	Arguments      string
+	Headers        http.Header
+	MessageCount   int
+	Stream         bool
+	Time           time.Time
+	// History lists tool calls made earlier in the conversation or session,
+	// oldest first.
+	History []string
+
+	// replayed is set for tool calls the client sends back as history; see
+	// EvaluateToolHistory.
+	replayed bool
 }
 
 func NewEngine(cfg config.PolicyConfig) *Engine {
+	identityHeader := cfg.IdentityHeader
+	if identityHeader == "" {
+		identityHeader = defaultIdentityHeader
+	}
 	return &Engine{
-		modelPolicy: cfg.Models,
-		toolPolicy:  cfg.Tools,
+		dryRun:         cfg.DryRun,
+		identityHeader: identityHeader,
+		sessionHeader:  cfg.SessionHeader,
+		sessions:       newSessionTracker(cfg.SessionTTL),
+		rules:          compileRules(cfg.Rules),
+		modelPolicy:    cfg.Models,
+		toolPolicy:     cfg.Tools,
+		constraints:    compileConstraints(cfg.Tools.Constraints),
+		content:        compileContentRules(cfg.Content),
+		catalog:        cfg.ToolCatalog,
+		modelParams:    cfg.ModelParams,
+		requestFields:  cfg.RequestFields,
+		images:         cfg.Images,
 	}
 }
 
 func (e *Engine) EvaluateModel(model string) Decision {
+	return e.EvaluateRequest(Input{Model: model})
+}
+
+func (e *Engine) EvaluateTool(toolName string) Decision {
+	return e.EvaluateToolDeclaration(Input{Tool: toolName})
+}
+
+func (e *Engine) EvaluateRequest(in Input) Decision {
+	return e.finalize(e.evaluateRequest(in))
+}
+
+func (e *Engine) EvaluateToolDeclaration(in Input) Decision {
+	return e.withTags(in, e.finalize(e.evaluateToolDeclaration(in)))
+}
+
+func (e *Engine) EvaluateToolCall(in Input) Decision {
+	decision := e.evaluateToolDeclaration(in)
+	if decision.Action == ActionRewrite {
+		rewritten, err := applyPatches(in.Arguments, decision.Patches)
+		if err != nil {
+			denied := NewDenyDecision(decision.RuleID, fmt.Sprintf("tool %q could not be rewritten: %v", in.Tool, err))
+			denied.Monitored = decision.Monitored
+			return e.withTags(in, e.finalize(denied))
+		}
+		decision.Arguments = rewritten
+		in.Arguments = rewritten
+	}
+	if decision.IsAllowed() || decision.RequiresApproval() {
+		decision = e.applyConstraints(in, decision)
+	}
+	return e.withTags(in, e.finalize(decision))
+}
+
+// EvaluateToolHistory evaluates a tool call the client sends back as
+// conversation history. Rules with time windows or called_before and
+// prior_calls conditions are skipped: they held, or not, when the call was
+// made, and judging them again now would strip calls that were legal then.
+func (e *Engine) EvaluateToolHistory(in Input) Decision {
+	in.replayed = true
+	in.History = nil
+	return e.EvaluateToolCall(in)
+}
+
+func (e *Engine) evaluateRequest(in Input) Decision {
+	r, ok, monitored := e.matchRule(in, false)
+	decision := e.evaluateModelLists(in)
+	if ok {
+		decision = ruleDecision(r, fmt.Sprintf("model %q", in.Model))
+	}
+	decision.Monitored = monitored
+	return decision
+}
+
+func (e *Engine) evaluateToolDeclaration(in Input) Decision {
+	r, ok, monitored := e.matchRule(in, true)
+	decision := e.evaluateToolLists(in.Tool)
+	if ok {
+		decision = ruleDecision(r, fmt.Sprintf("tool %q", in.Tool))
+	}
+	decision.Monitored = monitored
+	return decision
+}
+
+// Finalize applies the dry-run switch to a decision made outside the
+// engine.
+func (e *Engine) Finalize(decision Decision) Decision {
+	return e.finalize(decision)
+}
+
+// finalize applies the global dry-run switch: a deny is kept only as a
+// shadow decision and the request is allowed through.
+func (e *Engine) finalize(decision Decision) Decision {
+	if !e.dryRun || decision.Action == ActionAllow {
+		return decision
+	}
+
+	shadow := decision
+	shadow.Shadow = true
+	shadow.Monitored = nil
+	allowed := NewAllowDecision("DRY_RUN", "dry run: "+decision.Reason)
+	allowed.Monitored = append(decision.Monitored, shadow)
+	return allowed
+}
+
+func (e *Engine) evaluateModelLists(in Input) Decision {
+	model := in.Model
 	for _, denied := range e.modelPolicy.Deny {
-		if matchesPattern(model, denied) {
+		if modelMatches(in, denied) {
 			return NewDenyDecision(
 				"MODEL_DENY",
 				fmt.Sprintf("model %q is explicitly denied", model),
@@ -29,7 +166,7 @@ func (e *Engine) EvaluateModel(model string) Decision {
 	}
 
 	for _, allowed := range e.modelPolicy.Allow {
-		if matchesPattern(model, allowed) {
+		if modelMatches(in, allowed) {
 			return NewAllowDecision(
 				"MODEL_ALLOW",
 				fmt.Sprintf("model %q is explicitly allowed", model),
@@ -50,7 +187,7 @@ func (e *Engine) EvaluateModel(model string) Decision {
 	)
 }
 
-func (e *Engine) EvaluateTool(toolName string) Decision {
+func (e *Engine) evaluateToolLists(toolName string) Decision {
 	for _, denied := range e.toolPolicy.Deny {
 		if matchesPattern(toolName, denied) {
 			return NewDenyDecision(
@@ -82,13 +219,71 @@ func (e *Engine) EvaluateTool(toolName string) Decision {
 	)
 }
 
+func (e *Engine) DryRun() bool {
+	return e.dryRun
+}
+
+func (e *Engine) ToolEnforcement() string {
+	if e.toolPolicy.Enforcement == "" {
+		return config.ToolEnforcementReject
+	}
+	return e.toolPolicy.Enforcement
+}
+
+func (e *Engine) DeclaredToolsMode() string {
+	if e.toolPolicy.Declared == "" {
+		return config.DeclaredToolsRemove
+	}
+	return e.toolPolicy.Declared
+}
+
+func (e *Engine) UnknownFieldsMode() string {
+	if e.requestFields.Unknown == "" {
+		return config.UnknownFieldsReject
+	}
+	return e.requestFields.Unknown
+}
+
+func (e *Engine) ToolHistoryMode() string {
+	if e.toolPolicy.History == "" {
+		return config.ToolHistoryStrip
+	}
+	return e.toolPolicy.History
+}
+
+func (e *Engine) ToolValidationMode() string {
+	if e.toolPolicy.Validation == "" {
+		return config.ToolValidationOff
+	}
+	return e.toolPolicy.Validation
+}
+
 func matchesPattern(value, pattern string) bool {
 	if pattern == "*" {
 		return true
 	}
-	if len(pattern) > 0 && pattern[len(pattern)-1] == '*' {
-		prefix := pattern[:len(pattern)-1]
-		return len(value) >= len(prefix) && value[:len(prefix)] == prefix
+
+	// Iterative glob match where '*' spans any run of characters.
+	v, p := 0, 0
+	starP, starV := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			starP, starV = p, v
+			p++
+		case p < len(pattern) && pattern[p] == value[v]:
+			p++
+			v++
+		case starP >= 0:
+			p = starP + 1
+			starV++
+			v = starV
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
 	}
-	return value == pattern
+	return p == len(pattern)
 }
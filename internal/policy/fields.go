@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"strconv"
+	"strings"
+)
+
+// selectFields resolves a dot-separated path such as "options.limit" or
+// "urls.*" against decoded JSON. Numeric segments index arrays and "*" fans
+// out over every element.
+func selectFields(value interface{}, path string) []interface{} {
+	current := []interface{}{value}
+	if path == "" {
+		return current
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		next := make([]interface{}, 0, len(current))
+		for _, item := range current {
+			switch typed := item.(type) {
+			case map[string]interface{}:
+				if segment == "*" {
+					for _, child := range typed {
+						next = append(next, child)
+					}
+				} else if child, ok := typed[segment]; ok {
+					next = append(next, child)
+				}
+			case []interface{}:
+				if segment == "*" {
+					next = append(next, typed...)
+				} else if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(typed) {
+					next = append(next, typed[index])
+				}
+			}
+		}
+		current = next
+	}
+
+	return current
+}
+
+func stringValue(value interface{}) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(typed), true
+	}
+	return "", false
+}
@@ -0,0 +1,40 @@
+package policy
+
+import "fmt"
+
+// Image describes one image content part. Size is the decoded size of an
+// inline image in bytes, or -1 when it is not known.
+type Image struct {
+	Remote bool
+	Size   int
+}
+
+// EvaluateImage decides whether an image may be sent to the model in in.
+func (e *Engine) EvaluateImage(in Input, image Image) Decision {
+	return e.finalize(e.evaluateImage(in, image))
+}
+
+func (e *Engine) evaluateImage(in Input, image Image) Decision {
+	images := e.images
+	if modelMatchesAny(in, images.Deny) {
+		return NewDenyDecision("IMAGE_MODEL_DENY", fmt.Sprintf("images are denied for model %q", in.Model))
+	}
+	if len(images.Allow) > 0 && !modelMatchesAny(in, images.Allow) {
+		return NewDenyDecision("IMAGE_MODEL_NOT_ALLOWED", fmt.Sprintf("images are not allowed for model %q", in.Model))
+	}
+	if image.Remote {
+		if images.BlockRemoteURLs {
+			return NewDenyDecision("IMAGE_REMOTE_URL", "remote image URLs are not allowed")
+		}
+	} else if image.Size < 0 && images.Configured() {
+		return NewDenyDecision("IMAGE_INVALID", "inline image is not a base64 data URL")
+	} else if images.MaxBytes > 0 && image.Size > images.MaxBytes {
+		return NewDenyDecision("IMAGE_TOO_LARGE", fmt.Sprintf("image is %d bytes, the limit is %d", image.Size, images.MaxBytes))
+	}
+
+	return Decision{
+		Action: ActionAllow,
+		RuleID: "IMAGE_ALLOW",
+		Reason: "image allowed",
+	}
+}
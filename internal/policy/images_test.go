@@ -0,0 +1,49 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func TestEvaluateImage(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Images: config.ImagePolicy{
+			Allow:           []string{"gpt-4o*"},
+			Deny:            []string{"gpt-4o-mini"},
+			MaxBytes:        1024,
+			BlockRemoteURLs: true,
+		},
+	})
+
+	tests := []struct {
+		name       string
+		model      string
+		image      Image
+		wantAction string
+		wantRuleID string
+	}{
+		{name: "inline within limit", model: "gpt-4o", image: Image{Size: 512}, wantAction: ActionAllow, wantRuleID: "IMAGE_ALLOW"},
+		{name: "denied model", model: "gpt-4o-mini", image: Image{Size: 512}, wantAction: ActionDeny, wantRuleID: "IMAGE_MODEL_DENY"},
+		{name: "model not allowed", model: "gpt-3.5-turbo", image: Image{Size: 512}, wantAction: ActionDeny, wantRuleID: "IMAGE_MODEL_NOT_ALLOWED"},
+		{name: "too large", model: "gpt-4o", image: Image{Size: 2048}, wantAction: ActionDeny, wantRuleID: "IMAGE_TOO_LARGE"},
+		{name: "invalid inline", model: "gpt-4o", image: Image{Size: -1}, wantAction: ActionDeny, wantRuleID: "IMAGE_INVALID"},
+		{name: "remote url", model: "gpt-4o", image: Image{Remote: true, Size: -1}, wantAction: ActionDeny, wantRuleID: "IMAGE_REMOTE_URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateImage(Input{Model: tt.model}, tt.image)
+			if decision.Action != tt.wantAction || decision.RuleID != tt.wantRuleID {
+				t.Errorf("EvaluateImage() = %s/%s, want %s/%s", decision.Action, decision.RuleID, tt.wantAction, tt.wantRuleID)
+			}
+		})
+	}
+
+	open := NewEngine(config.PolicyConfig{})
+	for _, image := range []Image{{Remote: true, Size: -1}, {Size: -1}} {
+		if decision := open.EvaluateImage(Input{Model: "any"}, image); decision.Action != ActionAllow {
+			t.Errorf("EvaluateImage(%+v) with no image policy = %s, want allow", image, decision.Action)
+		}
+	}
+}
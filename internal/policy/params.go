@@ -0,0 +1,162 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+// ModelParams are the sampling parameters of a request. Nil means the
+// request leaves the parameter to the provider default.
+type ModelParams struct {
+	MaxTokens *int
+	// MaxCompletionTokens is held to the same ceiling as MaxTokens.
+	MaxCompletionTokens *int
+	Temperature         *float64
+	TopP                *float64
+	N                   *int
+	ResponseFormat      string
+}
+
+type ModelParamsResult struct {
+	Decisions []Decision
+	// Params are the input parameters with every enforced clamp applied.
+	Params ModelParams
+}
+
+func (r ModelParamsResult) Blocked() (Decision, bool) {
+	for _, decision := range r.Decisions {
+		if decision.Action == ActionDeny && !decision.Shadow {
+			return decision, true
+		}
+	}
+	return Decision{}, false
+}
+
+type paramViolation struct {
+	reason string
+	// clamp pulls the parameter back into range; nil when the violation
+	// cannot be clamped.
+	clamp func(*ModelParams)
+}
+
+// EvaluateModelParams checks the sampling parameters against every
+// model_params rule matching the model of in. Each violation yields a
+// decision.
+func (e *Engine) EvaluateModelParams(in Input, params ModelParams) ModelParamsResult {
+	result := ModelParamsResult{Params: params}
+	for _, r := range e.modelParams {
+		if len(r.Models) > 0 && !modelMatchesAny(in, r.Models) {
+			continue
+		}
+
+		for _, violation := range paramViolations(r, result.Params) {
+			reason := violation.reason
+			if r.Reason != "" {
+				reason = r.Reason + ": " + reason
+			}
+
+			if r.Action == config.ModelParamActionDeny || violation.clamp == nil {
+				decision := NewDenyDecision(r.ID, reason)
+				if r.Mode == config.RuleModeMonitor {
+					decision.Shadow = true
+					result.Decisions = append(result.Decisions, decision)
+				} else {
+					result.Decisions = append(result.Decisions, e.finalize(decision))
+				}
+				continue
+			}
+
+			decision := Decision{Action: ActionClamp, RuleID: r.ID, Reason: reason}
+			if e.dryRun || r.Mode == config.RuleModeMonitor {
+				decision.Shadow = true
+			} else {
+				violation.clamp(&result.Params)
+			}
+			result.Decisions = append(result.Decisions, decision)
+		}
+	}
+	return result
+}
+
+func paramViolations(r config.ModelParamRule, params ModelParams) []paramViolation {
+	var violations []paramViolation
+
+	if limit := r.MaxTokens; limit != nil {
+		if params.MaxTokens == nil && params.MaxCompletionTokens == nil {
+			violations = append(violations, paramViolation{
+				reason: fmt.Sprintf("max_tokens is required (at most %d)", *limit),
+				clamp:  func(p *ModelParams) { p.MaxTokens = intValue(*limit) },
+			})
+		}
+		if params.MaxTokens != nil && *params.MaxTokens > *limit {
+			violations = append(violations, paramViolation{
+				reason: fmt.Sprintf("max_tokens %d exceeds %d", *params.MaxTokens, *limit),
+				clamp:  func(p *ModelParams) { p.MaxTokens = intValue(*limit) },
+			})
+		}
+		if params.MaxCompletionTokens != nil && *params.MaxCompletionTokens > *limit {
+			violations = append(violations, paramViolation{
+				reason: fmt.Sprintf("max_completion_tokens %d exceeds %d", *params.MaxCompletionTokens, *limit),
+				clamp:  func(p *ModelParams) { p.MaxCompletionTokens = intValue(*limit) },
+			})
+		}
+	}
+	if v := rangeViolation("temperature", params.Temperature, r.Temperature); v != nil {
+		v.clamp = func(p *ModelParams) { p.Temperature = clampFloat(*p.Temperature, r.Temperature) }
+		violations = append(violations, *v)
+	}
+	if v := rangeViolation("top_p", params.TopP, r.TopP); v != nil {
+		v.clamp = func(p *ModelParams) { p.TopP = clampFloat(*p.TopP, r.TopP) }
+		violations = append(violations, *v)
+	}
+	if limit := r.MaxN; limit != nil && params.N != nil && *params.N > *limit {
+		violations = append(violations, paramViolation{
+			reason: fmt.Sprintf("n %d exceeds %d", *params.N, *limit),
+			clamp:  func(p *ModelParams) { p.N = intValue(*limit) },
+		})
+	}
+	if len(r.ResponseFormat) > 0 && !containsString(r.ResponseFormat, params.ResponseFormat) {
+		got := params.ResponseFormat
+		if got == "" {
+			got = "none"
+		}
+		violations = append(violations, paramViolation{
+			reason: fmt.Sprintf("response_format %s is not one of %v", got, r.ResponseFormat),
+		})
+	}
+
+	return violations
+}
+
+func rangeViolation(name string, value *float64, bounds *config.FloatRange) *paramViolation {
+	if value == nil || bounds == nil {
+		return nil
+	}
+	if bounds.Min != nil && *value < *bounds.Min {
+		return &paramViolation{reason: fmt.Sprintf("%s %s is below %s", name, formatFloat(*value), formatFloat(*bounds.Min))}
+	}
+	if bounds.Max != nil && *value > *bounds.Max {
+		return &paramViolation{reason: fmt.Sprintf("%s %s exceeds %s", name, formatFloat(*value), formatFloat(*bounds.Max))}
+	}
+	return nil
+}
+
+func clampFloat(value float64, bounds *config.FloatRange) *float64 {
+	if bounds.Min != nil && value < *bounds.Min {
+		value = *bounds.Min
+	}
+	if bounds.Max != nil && value > *bounds.Max {
+		value = *bounds.Max
+	}
+	return &value
+}
+
+func intValue(v int) *int {
+	return &v
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
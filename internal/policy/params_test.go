@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func TestEvaluateModelParams(t *testing.T) {
+	rules := []config.ModelParamRule{
+		{ID: "mini-budget", Models: []string{"gpt-4o-mini"}, Action: config.ModelParamActionClamp, MaxTokens: intPtr(1024), Temperature: &config.FloatRange{Min: floatPtr(0), Max: floatPtr(1)}, MaxN: intPtr(1)},
+		{ID: "strict-json", Models: []string{"gpt-4o"}, Action: config.ModelParamActionDeny, TopP: &config.FloatRange{Max: floatPtr(0.9)}, ResponseFormat: []string{"json_object", "json_schema"}},
+	}
+
+	tests := []struct {
+		name        string
+		model       string
+		params      ModelParams
+		wantActions []string
+		wantParams  ModelParams
+	}{
+		{
+			name:        "within bounds",
+			model:       "gpt-4o-mini",
+			params:      ModelParams{MaxTokens: intPtr(512), Temperature: floatPtr(0.2)},
+			wantParams:  ModelParams{MaxTokens: intPtr(512), Temperature: floatPtr(0.2)},
+			wantActions: nil,
+		},
+		{
+			name:        "clamps every violation",
+			model:       "gpt-4o-mini",
+			params:      ModelParams{MaxTokens: intPtr(4096), Temperature: floatPtr(1.7), N: intPtr(3)},
+			wantParams:  ModelParams{MaxTokens: intPtr(1024), Temperature: floatPtr(1), N: intPtr(1)},
+			wantActions: []string{ActionClamp, ActionClamp, ActionClamp},
+		},
+		{
+			name:        "missing max_tokens gets the ceiling",
+			model:       "gpt-4o-mini",
+			params:      ModelParams{},
+			wantParams:  ModelParams{MaxTokens: intPtr(1024)},
+			wantActions: []string{ActionClamp},
+		},
+		{
+			name:        "max_completion_tokens alone",
+			model:       "gpt-4o-mini",
+			params:      ModelParams{MaxCompletionTokens: intPtr(512)},
+			wantParams:  ModelParams{MaxCompletionTokens: intPtr(512)},
+			wantActions: nil,
+		},
+		{
+			name:        "both token fields are bounded",
+			model:       "gpt-4o-mini",
+			params:      ModelParams{MaxTokens: intPtr(10), MaxCompletionTokens: intPtr(100000)},
+			wantParams:  ModelParams{MaxTokens: intPtr(10), MaxCompletionTokens: intPtr(1024)},
+			wantActions: []string{ActionClamp},
+		},
+		{
+			name:        "deny rule",
+			model:       "gpt-4o",
+			params:      ModelParams{TopP: floatPtr(0.95), ResponseFormat: "json_object"},
+			wantParams:  ModelParams{TopP: floatPtr(0.95), ResponseFormat: "json_object"},
+			wantActions: []string{ActionDeny},
+		},
+		{
+			name:        "missing response format",
+			model:       "gpt-4o",
+			params:      ModelParams{},
+			wantParams:  ModelParams{},
+			wantActions: []string{ActionDeny},
+		},
+		{
+			name:       "other model",
+			model:      "gpt-3.5-turbo",
+			params:     ModelParams{MaxTokens: intPtr(8192)},
+			wantParams: ModelParams{MaxTokens: intPtr(8192)},
+		},
+	}
+
+	engine := NewEngine(config.PolicyConfig{ModelParams: rules})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := engine.EvaluateModelParams(Input{Model: tt.model}, tt.params)
+			if len(result.Decisions) != len(tt.wantActions) {
+				t.Fatalf("Decisions = %#v, want actions %v", result.Decisions, tt.wantActions)
+			}
+			for i, decision := range result.Decisions {
+				if decision.Action != tt.wantActions[i] {
+					t.Errorf("Decisions[%d].Action = %q, want %q", i, decision.Action, tt.wantActions[i])
+				}
+			}
+			if !equalParams(result.Params, tt.wantParams) {
+				t.Errorf("Params = %s, want %s", formatParams(result.Params), formatParams(tt.wantParams))
+			}
+		})
+	}
+}
+
+func TestEvaluateModelParams_MonitorAndDryRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+		mode   string
+		action string
+	}{
+		{"monitor clamp", false, config.RuleModeMonitor, config.ModelParamActionClamp},
+		{"monitor deny", false, config.RuleModeMonitor, config.ModelParamActionDeny},
+		{"dry run clamp", true, "", config.ModelParamActionClamp},
+		{"dry run deny", true, "", config.ModelParamActionDeny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(config.PolicyConfig{
+				DryRun:      tt.dryRun,
+				ModelParams: []config.ModelParamRule{{ID: "cap", Mode: tt.mode, Action: tt.action, MaxTokens: intPtr(100)}},
+			})
+			result := engine.EvaluateModelParams(Input{Model: "gpt-4o"}, ModelParams{MaxTokens: intPtr(500)})
+			if _, blocked := result.Blocked(); blocked {
+				t.Errorf("Blocked() = true, want false")
+			}
+			if *result.Params.MaxTokens != 500 {
+				t.Errorf("MaxTokens = %d, want 500", *result.Params.MaxTokens)
+			}
+		})
+	}
+}
+
+func equalParams(a, b ModelParams) bool {
+	return formatParams(a) == formatParams(b)
+}
+
+func formatParams(p ModelParams) string {
+	out := ""
+	if p.MaxTokens != nil {
+		out += " max_tokens=" + formatFloat(float64(*p.MaxTokens))
+	}
+	if p.MaxCompletionTokens != nil {
+		out += " max_completion_tokens=" + formatFloat(float64(*p.MaxCompletionTokens))
+	}
+	if p.Temperature != nil {
+		out += " temperature=" + formatFloat(*p.Temperature)
+	}
+	if p.TopP != nil {
+		out += " top_p=" + formatFloat(*p.TopP)
+	}
+	if p.N != nil {
+		out += " n=" + formatFloat(float64(*p.N))
+	}
+	return out + " response_format=" + p.ResponseFormat
+}
@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func applyPatches(arguments string, patches []config.ArgumentPatch) (string, error) {
+	var args interface{} = map[string]interface{}{}
+	if strings.TrimSpace(arguments) != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("arguments are not valid JSON")
+		}
+	}
+
+	before, _ := json.Marshal(args)
+	for _, patch := range patches {
+		args = applyPatch(args, strings.Split(patch.Field, "."), patch)
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("encoding rewritten arguments: %w", err)
+	}
+	// Keep the original text when no patch changed anything, so unchanged
+	// calls are passed through byte for byte.
+	if string(data) == string(before) && strings.TrimSpace(arguments) != "" {
+		return arguments, nil
+	}
+	return string(data), nil
+}
+
+func applyPatch(node interface{}, segments []string, patch config.ArgumentPatch) interface{} {
+	segment, rest := segments[0], segments[1:]
+
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		keys := []string{segment}
+		if segment == "*" {
+			keys = make([]string, 0, len(typed))
+			for key := range typed {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+		}
+		for _, key := range keys {
+			child, exists := typed[key]
+			if len(rest) == 0 {
+				switch patch.Op {
+				case config.PatchOpSet:
+					typed[key] = patch.Value
+				case config.PatchOpRemove:
+					delete(typed, key)
+				default:
+					if exists {
+						typed[key] = patchValue(child, patch)
+					}
+				}
+				continue
+			}
+			if !exists {
+				if patch.Op != config.PatchOpSet {
+					continue
+				}
+				child = map[string]interface{}{}
+			}
+			typed[key] = applyPatch(child, rest, patch)
+		}
+		return typed
+
+	case []interface{}:
+		indices := make([]int, 0, len(typed))
+		if segment == "*" {
+			for i := range typed {
+				indices = append(indices, i)
+			}
+		} else if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(typed) {
+			indices = append(indices, index)
+		}
+
+		removed := make(map[int]bool)
+		for _, i := range indices {
+			if len(rest) > 0 {
+				typed[i] = applyPatch(typed[i], rest, patch)
+				continue
+			}
+			switch patch.Op {
+			case config.PatchOpSet:
+				typed[i] = patch.Value
+			case config.PatchOpRemove:
+				removed[i] = true
+			default:
+				typed[i] = patchValue(typed[i], patch)
+			}
+		}
+		if len(removed) == 0 {
+			return typed
+		}
+		kept := make([]interface{}, 0, len(typed)-len(removed))
+		for i, item := range typed {
+			if !removed[i] {
+				kept = append(kept, item)
+			}
+		}
+		return kept
+	}
+
+	return node
+}
+
+// patchValue applies the value-level ops: clamp bounds a number, strip
+// drops matching shell words from a string or matching elements from an
+// array.
+func patchValue(value interface{}, patch config.ArgumentPatch) interface{} {
+	switch patch.Op {
+	case config.PatchOpClamp:
+		n, ok := value.(float64)
+		if !ok {
+			return value
+		}
+		if patch.Min != nil && n < *patch.Min {
+			n = *patch.Min
+		}
+		if patch.Max != nil && n > *patch.Max {
+			n = *patch.Max
+		}
+		return n
+	case config.PatchOpStrip:
+		switch typed := value.(type) {
+		case string:
+			return stripWords(typed, patch.Values)
+		case []interface{}:
+			kept := make([]interface{}, 0, len(typed))
+			for _, item := range typed {
+				if s, ok := stringValue(item); ok && containsString(patch.Values, s) {
+					continue
+				}
+				kept = append(kept, item)
+			}
+			return kept
+		}
+	}
+	return value
+}
+
+// stripWords removes the shell words of command whose unquoted value is one
+// of values, together with the whitespace before them. Everything else,
+// including quoting and the separators between kept words, is left as is.
+func stripWords(command string, values []string) string {
+	words := shellWords(command)
+	drop := make([]bool, len(command))
+	stripped := false
+	for i, word := range words {
+		if !containsString(values, word.value) {
+			continue
+		}
+		stripped = true
+		start, end := word.start, word.end
+		switch {
+		case i > 0:
+			start = words[i-1].end
+		case len(words) > 1:
+			end = words[1].start
+		}
+		for j := start; j < end; j++ {
+			drop[j] = true
+		}
+	}
+	if !stripped {
+		return command
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(command); i++ {
+		if !drop[i] {
+			b.WriteByte(command[i])
+		}
+	}
+	return b.String()
+}
+
+type shellWord struct {
+	start, end int
+	value      string
+}
+
+// shellWords splits a command line the way a POSIX shell would, honoring
+// single quotes, double quotes and backslash escapes. An unterminated quote
+// runs to the end of the string.
+func shellWords(command string) []shellWord {
+	var words []shellWord
+	i := 0
+	for i < len(command) {
+		for i < len(command) && isShellSpace(command[i]) {
+			i++
+		}
+		if i == len(command) {
+			break
+		}
+
+		start := i
+		var value strings.Builder
+		for i < len(command) && !isShellSpace(command[i]) {
+			switch c := command[i]; c {
+			case '\\':
+				if i+1 < len(command) {
+					value.WriteByte(command[i+1])
+					i++
+				}
+				i++
+			case '\'':
+				end := strings.IndexByte(command[i+1:], '\'')
+				if end < 0 {
+					value.WriteString(command[i+1:])
+					i = len(command)
+					break
+				}
+				value.WriteString(command[i+1 : i+1+end])
+				i += end + 2
+			case '"':
+				i++
+				for i < len(command) && command[i] != '"' {
+					if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("\\\"$`", command[i+1]) >= 0 {
+						i++
+					}
+					value.WriteByte(command[i])
+					i++
+				}
+				i++
+			default:
+				value.WriteByte(c)
+				i++
+			}
+		}
+		if i > len(command) {
+			i = len(command)
+		}
+		words = append(words, shellWord{start: start, end: i, value: value.String()})
+	}
+	return words
+}
+
+func isShellSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
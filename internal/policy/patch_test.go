@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func TestApplyPatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		patch     config.ArgumentPatch
+		want      string
+	}{
+		{"set", `{"env":"prod"}`, config.ArgumentPatch{Op: config.PatchOpSet, Field: "dry_run", Value: true}, `{"dry_run":true,"env":"prod"}`},
+		{"set creates parents", `{}`, config.ArgumentPatch{Op: config.PatchOpSet, Field: "options.dry_run", Value: true}, `{"options":{"dry_run":true}}`},
+		{"set on empty arguments", ``, config.ArgumentPatch{Op: config.PatchOpSet, Field: "dry_run", Value: true}, `{"dry_run":true}`},
+		{"remove", `{"force":true,"path":"/tmp"}`, config.ArgumentPatch{Op: config.PatchOpRemove, Field: "force"}, `{"path":"/tmp"}`},
+		{"remove array element", `{"ids":[1,2,3]}`, config.ArgumentPatch{Op: config.PatchOpRemove, Field: "ids.1"}, `{"ids":[1,3]}`},
+		{"clamp max", `{"limit":5000}`, config.ArgumentPatch{Op: config.PatchOpClamp, Field: "limit", Max: floatPtr(100)}, `{"limit":100}`},
+		{"clamp within range", `{"limit":5}`, config.ArgumentPatch{Op: config.PatchOpClamp, Field: "limit", Min: floatPtr(1), Max: floatPtr(100)}, `{"limit":5}`},
+		{"clamp missing field", `{}`, config.ArgumentPatch{Op: config.PatchOpClamp, Field: "limit", Max: floatPtr(100)}, `{}`},
+		{"clamp wildcard", `{"queries":[{"limit":500},{"limit":2}]}`, config.ArgumentPatch{Op: config.PatchOpClamp, Field: "queries.*.limit", Max: floatPtr(100)}, `{"queries":[{"limit":100},{"limit":2}]}`},
+		{"strip token", `{"command":"git push --force origin main"}`, config.ArgumentPatch{Op: config.PatchOpStrip, Field: "command", Values: []string{"--force", "-f"}}, `{"command":"git push origin main"}`},
+		{"strip keeps quoted arguments", `{"command":"git commit -f -m \"a  -f b\""}`, config.ArgumentPatch{Op: config.PatchOpStrip, Field: "command", Values: []string{"--force", "-f"}}, `{"command":"git commit -m \"a  -f b\""}`},
+		{"strip keeps separators", "{\"command\":\"git push\\t--force  origin 'my  branch'\"}", config.ArgumentPatch{Op: config.PatchOpStrip, Field: "command", Values: []string{"--force"}}, "{\"command\":\"git push  origin 'my  branch'\"}"},
+		{"strip quoted token", `{"command":"'--force' git push"}`, config.ArgumentPatch{Op: config.PatchOpStrip, Field: "command", Values: []string{"--force"}}, `{"command":"git push"}`},
+		{"strip escaped token", `{"command":"git push \\--force"}`, config.ArgumentPatch{Op: config.PatchOpStrip, Field: "command", Values: []string{"--force"}}, `{"command":"git push"}`},
+		{"strip array", `{"args":["push","-f","origin"]}`, config.ArgumentPatch{Op: config.PatchOpStrip, Field: "args", Values: []string{"--force", "-f"}}, `{"args":["push","origin"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatches(tt.arguments, []config.ArgumentPatch{tt.patch})
+			if err != nil {
+				t.Fatalf("applyPatches() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("applyPatches() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateToolCall_Rewrite(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{
+				ID:      "force-dry-run",
+				Action:  config.RuleActionRewrite,
+				Match:   config.RuleMatch{Tools: []string{"deploy"}},
+				Patches: []config.ArgumentPatch{{Op: config.PatchOpSet, Field: "dry_run", Value: true}},
+			},
+		},
+		Tools: config.ToolPolicy{
+			Allow:       []string{"*"},
+			Constraints: []config.ToolConstraint{{ID: "dry-run-only", Tool: "deploy", Field: "dry_run", Enum: []string{"true"}}},
+		},
+	})
+
+	decision := engine.EvaluateToolCall(Input{Tool: "deploy", Arguments: `{"env":"prod","dry_run":false}`})
+	if decision.Action != ActionRewrite || decision.RuleID != "force-dry-run" || !decision.IsAllowed() {
+		t.Fatalf("decision = %#v, want allowed rewrite", decision)
+	}
+	if decision.Arguments != `{"dry_run":true,"env":"prod"}` {
+		t.Errorf("Arguments = %s", decision.Arguments)
+	}
+
+	invalid := engine.EvaluateToolCall(Input{Tool: "deploy", Arguments: `not json`})
+	if invalid.Action != ActionDeny {
+		t.Errorf("decision for invalid arguments = %#v, want deny", invalid)
+	}
+}
@@ -0,0 +1,19 @@
+package policy
+
+import "fmt"
+
+// EvaluateRequestField decides whether a request field the gateway does not
+// model may be forwarded upstream.
+func (e *Engine) EvaluateRequestField(field string) Decision {
+	if matchesAny(field, e.requestFields.Passthrough) {
+		return e.finalize(Decision{
+			Action: ActionAllow,
+			RuleID: "REQUEST_FIELD_PASSTHROUGH",
+			Reason: fmt.Sprintf("request field %q is in the passthrough list", field),
+		})
+	}
+	return e.finalize(NewDenyDecision(
+		"REQUEST_FIELD_UNKNOWN",
+		fmt.Sprintf("request field %q is not supported", field),
+	))
+}
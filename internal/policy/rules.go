@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+type rule struct {
+	config.PolicyRule
+	window *timeWindow
+}
+
+type timeWindow struct {
+	start    time.Duration
+	end      time.Duration
+	hasClock bool
+	days     map[time.Weekday]bool
+	location *time.Location
+}
+
+func compileRules(rules []config.PolicyRule) []rule {
+	compiled := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		compiled = append(compiled, rule{PolicyRule: r, window: compileTimeWindow(r.Match.Time)})
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].Priority > compiled[j].Priority
+	})
+	return compiled
+}
+
+func compileTimeWindow(w *config.TimeWindow) *timeWindow {
+	if w == nil {
+		return nil
+	}
+
+	window := &timeWindow{location: time.UTC}
+	if w.Start != "" && w.End != "" {
+		start, errStart := config.ParseClock(w.Start)
+		end, errEnd := config.ParseClock(w.End)
+		if errStart == nil && errEnd == nil {
+			window.start, window.end, window.hasClock = start, end, true
+		}
+	}
+	if len(w.Days) > 0 {
+		window.days = make(map[time.Weekday]bool, len(w.Days))
+		for _, day := range w.Days {
+			if weekday, ok := config.ParseWeekday(day); ok {
+				window.days[weekday] = true
+			}
+		}
+	}
+	if w.Timezone != "" {
+		if location, err := time.LoadLocation(w.Timezone); err == nil {
+			window.location = location
+		}
+	}
+	return window
+}
+
+func (w *timeWindow) contains(t time.Time) bool {
+	local := t.In(w.location)
+	if w.days != nil && !w.days[local.Weekday()] {
+		return false
+	}
+	if !w.hasClock {
+		return true
+	}
+
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if w.start <= w.end {
+		return clock >= w.start && clock < w.end
+	}
+	return clock >= w.start || clock < w.end
+}
+
+// matchRule returns the first matching enforced rule. Matching monitor-mode
+// rules seen on the way are returned as shadow decisions.
+func (e *Engine) matchRule(in Input, forTool bool) (rule, bool, []Decision) {
+	var monitored []Decision
+	subject := fmt.Sprintf("model %q", in.Model)
+	if forTool {
+		subject = fmt.Sprintf("tool %q", in.Tool)
+	}
+
+	for _, r := range e.rules {
+		if r.Match.IsToolRule() != forTool {
+			continue
+		}
+		if !e.ruleMatches(r, in) {
+			continue
+		}
+		if r.Mode == config.RuleModeMonitor {
+			shadow := ruleDecision(r, subject)
+			shadow.Shadow = true
+			monitored = append(monitored, shadow)
+			continue
+		}
+		return r, true, monitored
+	}
+	return rule{}, false, monitored
+}
+
+func (e *Engine) ruleMatches(r rule, in Input) bool {
+	m := r.Match
+	if in.replayed && (len(m.CalledBefore) > 0 || m.PriorCalls != nil || r.window != nil) {
+		return false
+	}
+	if len(m.Models) > 0 && !modelMatchesAny(in, m.Models) {
+		return false
+	}
+	if len(m.Tools) > 0 && !matchesAny(in.Tool, m.Tools) {
+		return false
+	}
+	if len(m.Tags) > 0 && !sharesAny(e.ToolTags(in.Tool), m.Tags) {
+		return false
+	}
+	if len(m.Providers) > 0 && !matchesAny(in.Provider, m.Providers) {
+		return false
+	}
+	if len(m.Callers) > 0 && !matchesAny(e.Caller(in), m.Callers) {
+		return false
+	}
+	for name, pattern := range m.Headers {
+		if !matchesPattern(in.Headers.Get(name), pattern) {
+			return false
+		}
+	}
+	if m.MessageCount != nil && !inRange(in.MessageCount, m.MessageCount) {
+		return false
+	}
+	if m.Stream != nil && *m.Stream != in.Stream {
+		return false
+	}
+	if len(m.CalledBefore) > 0 && !calledBefore(in.History, m.CalledBefore) {
+		return false
+	}
+	if m.PriorCalls != nil && !inRange(countCalls(in.History, in.Tool), m.PriorCalls) {
+		return false
+	}
+	if r.window != nil {
+		now := in.Time
+		if now.IsZero() {
+			now = time.Now()
+		}
+		if !r.window.contains(now) {
+			return false
+		}
+	}
+	return true
+}
+
+func ruleDecision(r rule, subject string) Decision {
+	reason := r.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("%s matched rule %q", subject, r.ID)
+	}
+	return Decision{
+		Action:  r.Action,
+		RuleID:  r.ID,
+		Reason:  reason,
+		Patches: r.Patches,
+	}
+}
+
+func calledBefore(history, patterns []string) bool {
+	for _, name := range history {
+		if matchesAny(name, patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+func countCalls(history []string, toolName string) int {
+	count := 0
+	for _, name := range history {
+		if name == toolName {
+			count++
+		}
+	}
+	return count
+}
+
+func inRange(value int, r *config.IntRange) bool {
+	if r.Min != nil && value < *r.Min {
+		return false
+	}
+	if r.Max != nil && value > *r.Max {
+		return false
+	}
+	return true
+}
+
+func matchesAny(value string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchesPattern(value, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func modelMatches(in Input, pattern string) bool {
+	return matchesPattern(in.Model, pattern) || (in.RequestedModel != "" && matchesPattern(in.RequestedModel, pattern))
+}
+
+func modelMatchesAny(in Input, patterns []string) bool {
+	for _, pattern := range patterns {
+		if modelMatches(in, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// IdentityHeader returns the header that carries the caller identity.
+func (e *Engine) IdentityHeader() string {
+	return e.identityHeader
+}
+
+func (e *Engine) Caller(in Input) string {
+	if in.Headers == nil {
+		return ""
+	}
+	return strings.TrimSpace(in.Headers.Get(e.identityHeader))
+}
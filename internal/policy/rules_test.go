@@ -0,0 +1,229 @@
+package policy
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestEvaluateRequest_RulePriorityAndFirstMatch(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "allow-gpt4", Priority: 10, Action: config.RuleActionAllow, Match: config.RuleMatch{Models: []string{"gpt-4*"}}},
+			{ID: "deny-streaming-mini", Priority: 20, Action: config.RuleActionDeny, Match: config.RuleMatch{Models: []string{"gpt-4o-mini"}, Stream: boolPtr(true)}},
+			{ID: "deny-long-history", Priority: 10, Action: config.RuleActionDeny, Match: config.RuleMatch{MessageCount: &config.IntRange{Min: intPtr(100)}}},
+		},
+	})
+
+	tests := []struct {
+		name       string
+		in         Input
+		wantAction string
+		wantRule   string
+	}{
+		{"higher priority wins", Input{Model: "gpt-4o-mini", Stream: true}, ActionDeny, "deny-streaming-mini"},
+		{"first match among equal priority", Input{Model: "gpt-4o", MessageCount: 200}, ActionAllow, "allow-gpt4"},
+		{"later rule matches", Input{Model: "claude", MessageCount: 200}, ActionDeny, "deny-long-history"},
+		{"falls back to model lists", Input{Model: "claude"}, ActionDeny, "MODEL_DEFAULT_DENY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateRequest(tt.in)
+			if decision.Action != tt.wantAction || decision.RuleID != tt.wantRule {
+				t.Errorf("EvaluateRequest() = %s/%s, want %s/%s", decision.Action, decision.RuleID, tt.wantAction, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestEvaluateToolDeclaration_RuleConditions(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		IdentityHeader: "X-Caller",
+		Rules: []config.PolicyRule{
+			{ID: "ops-shell", Action: config.RuleActionAllow, Match: config.RuleMatch{Tools: []string{"shell_*"}, Callers: []string{"ops-*"}, Providers: []string{"openai"}}},
+			{ID: "prod-no-shell", Action: config.RuleActionDeny, Match: config.RuleMatch{Tools: []string{"shell_*"}, Headers: map[string]string{"X-Env": "prod"}}},
+			{ID: "model-only", Action: config.RuleActionDeny, Match: config.RuleMatch{Models: []string{"*"}}},
+		},
+		Tools: config.ToolPolicy{Allow: []string{"*"}},
+	})
+
+	opsHeaders := http.Header{}
+	opsHeaders.Set("X-Caller", "ops-bot")
+	prodHeaders := http.Header{}
+	prodHeaders.Set("X-Env", "prod")
+
+	tests := []struct {
+		name     string
+		in       Input
+		wantRule string
+	}{
+		{"caller and provider match", Input{Tool: "shell_exec", Provider: "openai", Headers: opsHeaders}, "ops-shell"},
+		{"header condition", Input{Tool: "shell_exec", Provider: "bedrock", Headers: prodHeaders}, "prod-no-shell"},
+		{"model-only rules ignored for tools", Input{Tool: "search_web", Model: "gpt-4o"}, "TOOL_ALLOW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateToolDeclaration(tt.in)
+			if decision.RuleID != tt.wantRule {
+				t.Errorf("EvaluateToolDeclaration() rule = %q, want %q", decision.RuleID, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestEvaluateRequest_TimeWindow(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "after-hours", Action: config.RuleActionDeny, Match: config.RuleMatch{Time: &config.TimeWindow{Start: "18:00", End: "09:00", Timezone: "UTC"}}},
+			{ID: "weekend", Action: config.RuleActionDeny, Match: config.RuleMatch{Time: &config.TimeWindow{Days: []string{"sat", "sun"}}}},
+		},
+		Models: config.ModelPolicy{Allow: []string{"*"}},
+	})
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		wantRule string
+	}{
+		{"business hours", time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC), "MODEL_ALLOW"},
+		{"late evening", time.Date(2026, 3, 4, 22, 0, 0, 0, time.UTC), "after-hours"},
+		{"early morning", time.Date(2026, 3, 4, 7, 30, 0, 0, time.UTC), "after-hours"},
+		{"saturday", time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC), "weekend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateRequest(Input{Model: "gpt-4o", Time: tt.at})
+			if decision.RuleID != tt.wantRule {
+				t.Errorf("EvaluateRequest() rule = %q, want %q", decision.RuleID, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestMatchesPattern_Glob(t *testing.T) {
+	tests := []struct {
+		value   string
+		pattern string
+		want    bool
+	}{
+		{"anthropic.claude-3-5-sonnet-20240620-v1:0", "anthropic.*-v1:0", true},
+		{"openai/gpt-4o", "*/gpt-4*", true},
+		{"gpt-4o", "*mini", false},
+		{"gpt-4o", "gpt-4o", true},
+		{"", "*", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchesPattern(tt.value, tt.pattern); got != tt.want {
+			t.Errorf("matchesPattern(%q, %q) = %v, want %v", tt.value, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateRequest_MonitorRule(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "new-deny", Priority: 10, Mode: config.RuleModeMonitor, Action: config.RuleActionDeny, Match: config.RuleMatch{Models: []string{"gpt-4o"}}},
+		},
+		Models: config.ModelPolicy{Allow: []string{"gpt-4o"}},
+	})
+
+	decision := engine.EvaluateRequest(Input{Model: "gpt-4o"})
+	if !decision.IsAllowed() || decision.RuleID != "MODEL_ALLOW" {
+		t.Fatalf("EvaluateRequest() = %s/%s, want allow/MODEL_ALLOW", decision.Action, decision.RuleID)
+	}
+	if len(decision.Monitored) != 1 {
+		t.Fatalf("Monitored len = %d, want 1", len(decision.Monitored))
+	}
+	shadow := decision.Monitored[0]
+	if shadow.RuleID != "new-deny" || shadow.Action != ActionDeny || !shadow.Shadow {
+		t.Errorf("shadow decision = %#v", shadow)
+	}
+}
+
+func TestEvaluateToolCall_MonitorConstraint(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Tools: config.ToolPolicy{
+			Allow:       []string{"*"},
+			Constraints: []config.ToolConstraint{{ID: "small-deletes", Tool: "delete_records", Mode: config.RuleModeMonitor, Field: "limit", Max: floatPtr(10)}},
+		},
+	})
+
+	decision := engine.EvaluateToolCall(Input{Tool: "delete_records", Arguments: `{"limit":50}`})
+	if !decision.IsAllowed() {
+		t.Fatalf("EvaluateToolCall() = %#v, want allowed", decision)
+	}
+	if len(decision.Monitored) != 1 || decision.Monitored[0].Constraint != "small-deletes" {
+		t.Errorf("Monitored = %#v, want shadow small-deletes", decision.Monitored)
+	}
+}
+
+func TestEvaluate_DryRun(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		DryRun: true,
+		Models: config.ModelPolicy{Allow: []string{"gpt-4o"}},
+		Tools:  config.ToolPolicy{Deny: []string{"shell_exec"}},
+	})
+
+	decision := engine.EvaluateRequest(Input{Model: "gpt-3.5-turbo"})
+	if !decision.IsAllowed() || decision.RuleID != "DRY_RUN" {
+		t.Fatalf("EvaluateRequest() = %s/%s, want allow/DRY_RUN", decision.Action, decision.RuleID)
+	}
+	if len(decision.Monitored) != 1 || decision.Monitored[0].RuleID != "MODEL_DEFAULT_DENY" || !decision.Monitored[0].Shadow {
+		t.Errorf("Monitored = %#v, want shadow MODEL_DEFAULT_DENY", decision.Monitored)
+	}
+
+	decision = engine.EvaluateToolCall(Input{Tool: "shell_exec"})
+	if !decision.IsAllowed() || decision.Monitored[0].RuleID != "TOOL_DENY" {
+		t.Errorf("EvaluateToolCall() = %#v, want dry-run allow with TOOL_DENY shadow", decision)
+	}
+
+	decision = engine.EvaluateRequest(Input{Model: "gpt-4o"})
+	if decision.RuleID != "MODEL_ALLOW" || len(decision.Monitored) != 0 {
+		t.Errorf("EvaluateRequest() = %#v, want plain MODEL_ALLOW", decision)
+	}
+}
+
+func TestEvaluateRequest_ModelAlias(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "no-streaming-cheap", Action: config.RuleActionDeny, Match: config.RuleMatch{Models: []string{"cheap-*"}, Stream: boolPtr(true)}},
+		},
+		Models: config.ModelPolicy{Allow: []string{"default-chat", "gpt-4o"}, Deny: []string{"gpt-3.5*"}},
+	})
+
+	tests := []struct {
+		name       string
+		in         Input
+		wantAction string
+		wantRule   string
+	}{
+		{"alias allowed", Input{Model: "gpt-4o-mini", RequestedModel: "default-chat"}, ActionAllow, "MODEL_ALLOW"},
+		{"resolved allowed", Input{Model: "gpt-4o", RequestedModel: "cheap-fast"}, ActionAllow, "MODEL_ALLOW"},
+		{"resolved denied", Input{Model: "gpt-3.5-turbo", RequestedModel: "default-chat"}, ActionDeny, "MODEL_DENY"},
+		{"rule on alias", Input{Model: "gpt-4o", RequestedModel: "cheap-fast", Stream: true}, ActionDeny, "no-streaming-cheap"},
+		{"no alias", Input{Model: "gpt-4o-mini"}, ActionDeny, "MODEL_DEFAULT_DENY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateRequest(tt.in)
+			if decision.Action != tt.wantAction || decision.RuleID != tt.wantRule {
+				t.Errorf("EvaluateRequest() = %s/%s, want %s/%s", decision.Action, decision.RuleID, tt.wantAction, tt.wantRule)
+			}
+		})
+	}
+}
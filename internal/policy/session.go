@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultSessionTTL = 24 * time.Hour
+
+// Session IDs come from a client header, so both the number of sessions
+// and the history kept per session are bounded. A full session keeps the
+// tools it already recorded and stops adding new names; counts stop at
+// maxSessionCalls.
+const (
+	maxSessions        = 10000
+	maxSessionTools    = 256
+	maxSessionCalls    = 1024
+	sessionPrunePeriod = time.Minute
+)
+
+type sessionTracker struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	sessions  map[string]*sessionState
+	lastPrune time.Time
+}
+
+// sessionState keeps each tool name in first-call order with its number of
+// calls, which is all the sequencing rules look at.
+type sessionState struct {
+	order    []string
+	calls    map[string]int
+	lastSeen time.Time
+}
+
+func newSessionTracker(ttl time.Duration) *sessionTracker {
+	if ttl <= 0 {
+		ttl = defaultSessionTTL
+	}
+	return &sessionTracker{
+		ttl:      ttl,
+		sessions: make(map[string]*sessionState),
+	}
+}
+
+func (t *sessionTracker) history(id string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.sessions[id]
+	if !ok || time.Since(state.lastSeen) > t.ttl {
+		return nil
+	}
+	history := make([]string, 0, len(state.order))
+	for _, name := range state.order {
+		for i := 0; i < state.calls[name]; i++ {
+			history = append(history, name)
+		}
+	}
+	return history
+}
+
+func (t *sessionTracker) record(id string, tools []string) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastPrune) > sessionPrunePeriod {
+		t.prune(now)
+	}
+
+	state, ok := t.sessions[id]
+	if !ok || now.Sub(state.lastSeen) > t.ttl {
+		if !ok && len(t.sessions) >= maxSessions {
+			t.prune(now)
+			if len(t.sessions) >= maxSessions {
+				t.evictOldest()
+			}
+		}
+		state = &sessionState{calls: make(map[string]int)}
+		t.sessions[id] = state
+	}
+	for _, name := range tools {
+		count, seen := state.calls[name]
+		switch {
+		case !seen && len(state.order) >= maxSessionTools:
+			continue
+		case !seen:
+			state.order = append(state.order, name)
+		}
+		state.calls[name] = min(count+1, maxSessionCalls)
+	}
+	state.lastSeen = now
+}
+
+func (t *sessionTracker) prune(now time.Time) {
+	for key, s := range t.sessions {
+		if now.Sub(s.lastSeen) > t.ttl {
+			delete(t.sessions, key)
+		}
+	}
+	t.lastPrune = now
+}
+
+func (t *sessionTracker) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, s := range t.sessions {
+		if oldestKey == "" || s.lastSeen.Before(oldest) {
+			oldestKey, oldest = key, s.lastSeen
+		}
+	}
+	delete(t.sessions, oldestKey)
+}
+
+func (e *Engine) Session(in Input) string {
+	if in.Headers == nil || e.sessionHeader == "" {
+		return ""
+	}
+	return in.Headers.Get(e.sessionHeader)
+}
+
+// SessionHistory returns the tool calls the gateway has already let through
+// for a session, grouped by tool in the order each tool was first called.
+func (e *Engine) SessionHistory(session string) []string {
+	if session == "" {
+		return nil
+	}
+	return e.sessions.history(session)
+}
+
+func (e *Engine) RecordToolCalls(session string, tools []string) {
+	if session == "" || len(tools) == 0 {
+		return
+	}
+	e.sessions.record(session, tools)
+}
@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func TestEvaluateToolCall_SequencingRules(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "no-exfil-after-secrets", Action: config.RuleActionDeny, Match: config.RuleMatch{Tools: []string{"http_post"}, CalledBefore: []string{"read_secrets"}}},
+			{ID: "max-three-searches", Action: config.RuleActionDeny, Match: config.RuleMatch{Tools: []string{"search_web"}, PriorCalls: &config.IntRange{Min: intPtr(3)}}},
+		},
+		Tools: config.ToolPolicy{Allow: []string{"*"}},
+	})
+
+	tests := []struct {
+		name     string
+		tool     string
+		history  []string
+		wantRule string
+	}{
+		{"post without secrets", "http_post", []string{"search_web"}, "TOOL_ALLOW"},
+		{"post after secrets", "http_post", []string{"read_secrets", "search_web"}, "no-exfil-after-secrets"},
+		{"third search", "search_web", []string{"search_web", "search_web"}, "TOOL_ALLOW"},
+		{"fourth search", "search_web", []string{"search_web", "search_web", "search_web"}, "max-three-searches"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateToolCall(Input{Tool: tt.tool, History: tt.history})
+			if decision.RuleID != tt.wantRule {
+				t.Errorf("EvaluateToolCall() rule = %q, want %q", decision.RuleID, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestEngine_SessionHistory(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{SessionHeader: "X-Session-ID", SessionTTL: time.Hour})
+
+	headers := http.Header{}
+	headers.Set("X-Session-ID", "session-1")
+	session := engine.Session(Input{Headers: headers})
+	if session != "session-1" {
+		t.Fatalf("Session() = %q, want session-1", session)
+	}
+
+	engine.RecordToolCalls(session, []string{"read_secrets"})
+	engine.RecordToolCalls(session, []string{"search_web", "search_web"})
+
+	history := engine.SessionHistory(session)
+	if len(history) != 3 || history[0] != "read_secrets" {
+		t.Errorf("SessionHistory() = %v", history)
+	}
+	if len(engine.SessionHistory("other")) != 0 {
+		t.Errorf("SessionHistory() for unknown session should be empty")
+	}
+}
+
+func TestSessionTracker_Bounds(t *testing.T) {
+	tracker := newSessionTracker(time.Hour)
+
+	tools := make([]string, 0, maxSessionTools+10)
+	for i := 0; i < maxSessionTools+10; i++ {
+		tools = append(tools, fmt.Sprintf("tool_%d", i))
+	}
+	tracker.record("s", []string{"read_secrets"})
+	tracker.record("s", tools)
+	for i := 0; i < maxSessionCalls+10; i++ {
+		tracker.record("s", []string{"read_secrets"})
+	}
+
+	history := tracker.history("s")
+	if want := maxSessionTools - 1 + maxSessionCalls; len(history) != want {
+		t.Errorf("history length = %d, want %d", len(history), want)
+	}
+	if history[0] != "read_secrets" {
+		t.Errorf("history[0] = %q, want the first recorded tool kept", history[0])
+	}
+
+	for i := 0; i < maxSessions+5; i++ {
+		tracker.record(fmt.Sprintf("session-%d", i), []string{"search_web"})
+	}
+	if len(tracker.sessions) > maxSessions {
+		t.Errorf("sessions = %d, want at most %d", len(tracker.sessions), maxSessions)
+	}
+}
@@ -0,0 +1,44 @@
+package policy
+
+import "sort"
+
+// ToolTags returns the catalog tags of a tool: the union of every entry
+// whose patterns match the name, or the default tags when none do.
+func (e *Engine) ToolTags(toolName string) []string {
+	if toolName == "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, entry := range e.catalog.Tools {
+		if !matchesAny(toolName, entry.Match) {
+			continue
+		}
+		for _, tag := range entry.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if len(tags) == 0 {
+		tags = append(tags, e.catalog.DefaultTags...)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func sharesAny(tags, wanted []string) bool {
+	for _, tag := range tags {
+		if containsString(wanted, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) withTags(in Input, decision Decision) Decision {
+	decision.Tags = e.ToolTags(in.Tool)
+	return decision
+}
@@ -0,0 +1,82 @@
+package policy
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func TestToolTags(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		ToolCatalog: config.ToolCatalog{
+			DefaultTags: []string{"untagged"},
+			Tools: []config.ToolTagEntry{
+				{Match: []string{"delete_*", "drop_table"}, Tags: []string{"destructive", "database"}},
+				{Match: []string{"http_*"}, Tags: []string{"network"}},
+				{Match: []string{"http_post"}, Tags: []string{"egress", "network"}},
+			},
+		},
+	})
+
+	tests := []struct {
+		tool string
+		want []string
+	}{
+		{"delete_records", []string{"database", "destructive"}},
+		{"http_get", []string{"network"}},
+		{"http_post", []string{"egress", "network"}},
+		{"read_file", []string{"untagged"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			if got := engine.ToolTags(tt.tool); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToolTags(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateToolCall_TagRules(t *testing.T) {
+	engine := NewEngine(config.PolicyConfig{
+		IdentityHeader: "X-Caller",
+		ToolCatalog: config.ToolCatalog{
+			Tools: []config.ToolTagEntry{
+				{Match: []string{"delete_*"}, Tags: []string{"destructive"}},
+				{Match: []string{"http_*", "fetch_url"}, Tags: []string{"network"}},
+			},
+		},
+		Rules: []config.PolicyRule{
+			{ID: "no-destructive-bots", Priority: 10, Action: config.RuleActionDeny, Match: config.RuleMatch{Tags: []string{"destructive"}, Callers: []string{"bot-*"}}},
+			{ID: "approve-network", Action: config.RuleActionRequireApproval, Match: config.RuleMatch{Tags: []string{"network"}}},
+		},
+		Tools: config.ToolPolicy{Allow: []string{"*"}},
+	})
+
+	tests := []struct {
+		name       string
+		in         Input
+		wantAction string
+		wantRule   string
+	}{
+		{"tag and caller match", Input{Tool: "delete_user", Headers: http.Header{"X-Caller": {"bot-7"}}}, ActionDeny, "no-destructive-bots"},
+		{"tag without caller", Input{Tool: "delete_user", Headers: http.Header{"X-Caller": {"ops"}}}, ActionAllow, "TOOL_ALLOW"},
+		{"tag across tool names", Input{Tool: "fetch_url"}, ActionRequireApproval, "approve-network"},
+		{"untagged tool", Input{Tool: "read_file"}, ActionAllow, "TOOL_ALLOW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.EvaluateToolCall(tt.in)
+			if decision.Action != tt.wantAction || decision.RuleID != tt.wantRule {
+				t.Errorf("EvaluateToolCall() = %s/%s, want %s/%s", decision.Action, decision.RuleID, tt.wantAction, tt.wantRule)
+			}
+			if !reflect.DeepEqual(decision.Tags, engine.ToolTags(tt.in.Tool)) {
+				t.Errorf("Tags = %v, want %v", decision.Tags, engine.ToolTags(tt.in.Tool))
+			}
+		})
+	}
+}
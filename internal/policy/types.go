@@ -1,15 +1,42 @@
 package policy
 
+import "github.com/alereyleyva/agent-guard/internal/config"
+
 type Decision struct {
-	Action string `json:"action"`
-	RuleID string `json:"rule_id"`
-	Reason string `json:"reason"`
+	Action     string `json:"action"`
+	RuleID     string `json:"rule_id"`
+	Reason     string `json:"reason"`
+	Constraint string `json:"constraint,omitempty"`
+	Shadow     bool   `json:"shadow,omitempty"`
+	// Tags are the catalog tags of the evaluated tool.
+	Tags []string `json:"tags,omitempty"`
+	// Monitored holds shadow decisions from monitor-mode rules or dry run
+	// that were computed along the way but not enforced.
+	Monitored []Decision `json:"-"`
+	// Patches and Arguments are set for rewrite decisions; Arguments holds
+	// the rewritten tool call arguments.
+	Patches   []config.ArgumentPatch `json:"-"`
+	Arguments string                 `json:"-"`
 }
 
 const ActionAllow = "allow"
 
 const ActionDeny = "deny"
 
+const ActionRequireApproval = "require_approval"
+
+const ActionRewrite = "rewrite"
+
+const ActionFlag = "flag"
+
+const ActionMask = "mask"
+
+const ActionQuarantine = "quarantine"
+
+const ActionAnnotate = "annotate"
+
+const ActionClamp = "clamp"
+
 func NewAllowDecision(ruleID, reason string) Decision {
 	return Decision{
 		Action: ActionAllow,
@@ -26,6 +53,12 @@ func NewDenyDecision(ruleID, reason string) Decision {
 	}
 }
 
+// IsAllowed reports whether the call may proceed; a rewrite proceeds with
+// modified arguments.
 func (d Decision) IsAllowed() bool {
-	return d.Action == ActionAllow
+	return d.Action == ActionAllow || d.Action == ActionRewrite
+}
+
+func (d Decision) RequiresApproval() bool {
+	return d.Action == ActionRequireApproval
 }
@@ -0,0 +1,217 @@
+// Package policytest runs fixture cases through a policy engine so policy
+// changes can be checked offline.
+package policytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+type Suite struct {
+	Cases []Case `yaml:"cases"`
+}
+
+// Case describes one request, or one tool call when Tool is set, and the
+// decision the policy is expected to make.
+type Case struct {
+	Name     string            `yaml:"name"`
+	Model    string            `yaml:"model"`
+	Tool     string            `yaml:"tool"`
+	Identity string            `yaml:"identity"`
+	Headers  map[string]string `yaml:"headers"`
+	Stream   bool              `yaml:"stream"`
+	History  []string          `yaml:"history"`
+	Time     string            `yaml:"time"`
+	// Arguments may be given as a JSON string or as a YAML mapping.
+	Arguments interface{} `yaml:"arguments"`
+	Expect    Expect      `yaml:"expect"`
+}
+
+type Expect struct {
+	Action string `yaml:"action"`
+	RuleID string `yaml:"rule_id"`
+	// Constraint is the ID of the tool constraint behind a TOOL_CONSTRAINT
+	// decision.
+	Constraint string `yaml:"constraint"`
+	// Arguments, when set, is compared as JSON with the rewritten
+	// arguments of a rewrite decision.
+	Arguments interface{} `yaml:"arguments"`
+}
+
+type Result struct {
+	Case     Case
+	Decision policy.Decision
+	Passed   bool
+	// Diff lists one line per mismatched field, or the error that kept the
+	// case from running.
+	Diff []string
+}
+
+func LoadSuite(path string) (Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Suite{}, fmt.Errorf("reading fixtures: %w", err)
+	}
+
+	var suite Suite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return Suite{}, fmt.Errorf("parsing fixtures: %w", err)
+	}
+	for i, c := range suite.Cases {
+		if c.Expect.Action == "" {
+			return Suite{}, fmt.Errorf("case %d (%s): expect.action is required", i, c.Name)
+		}
+	}
+	return suite, nil
+}
+
+// Run evaluates every case against a fresh engine built from cfg. Model
+// aliases are resolved the same way the gateway does.
+func Run(cfg config.Config, suite Suite) []Result {
+	engine := policy.NewEngine(cfg.Policy)
+	results := make([]Result, 0, len(suite.Cases))
+	for _, c := range suite.Cases {
+		results = append(results, runCase(engine, cfg.ModelAliases, c))
+	}
+	return results
+}
+
+func runCase(engine *policy.Engine, aliases map[string]string, c Case) Result {
+	result := Result{Case: c}
+
+	in, err := buildInput(engine, aliases, c)
+	if err != nil {
+		result.Diff = []string{err.Error()}
+		return result
+	}
+
+	if c.Tool == "" {
+		result.Decision = engine.EvaluateRequest(in)
+	} else {
+		result.Decision = engine.EvaluateToolCall(in)
+	}
+
+	if result.Decision.Action != c.Expect.Action {
+		result.Diff = append(result.Diff, fmt.Sprintf("action: want %q, got %q", c.Expect.Action, result.Decision.Action))
+	}
+	if c.Expect.RuleID != "" && result.Decision.RuleID != c.Expect.RuleID {
+		result.Diff = append(result.Diff, fmt.Sprintf("rule_id: want %q, got %q", c.Expect.RuleID, result.Decision.RuleID))
+	}
+	if c.Expect.Constraint != "" && result.Decision.Constraint != c.Expect.Constraint {
+		result.Diff = append(result.Diff, fmt.Sprintf("constraint: want %q, got %q", c.Expect.Constraint, result.Decision.Constraint))
+	}
+	if c.Expect.Arguments != nil {
+		want, err := argumentsJSON(c.Expect.Arguments)
+		if err != nil {
+			result.Diff = append(result.Diff, fmt.Sprintf("expect.arguments: %v", err))
+		} else if got := result.Decision.Arguments; !equalJSON(want, got) {
+			result.Diff = append(result.Diff, fmt.Sprintf("arguments: want %s, got %s", want, got))
+		}
+	}
+	result.Passed = len(result.Diff) == 0
+	return result
+}
+
+func buildInput(engine *policy.Engine, aliases map[string]string, c Case) (policy.Input, error) {
+	in := policy.Input{
+		Model:   c.Model,
+		Tool:    c.Tool,
+		Headers: http.Header{},
+		Stream:  c.Stream,
+		History: c.History,
+		Time:    time.Now(),
+	}
+	if resolved, ok := aliases[c.Model]; ok {
+		in.Model = resolved
+		in.RequestedModel = c.Model
+	}
+	for name, value := range c.Headers {
+		in.Headers.Set(name, value)
+	}
+	if c.Identity != "" {
+		in.Headers.Set(engine.IdentityHeader(), c.Identity)
+	}
+	if c.Time != "" {
+		t, err := time.Parse(time.RFC3339, c.Time)
+		if err != nil {
+			return in, fmt.Errorf("time: %w", err)
+		}
+		in.Time = t
+	}
+	if c.Arguments != nil {
+		arguments, err := argumentsJSON(c.Arguments)
+		if err != nil {
+			return in, fmt.Errorf("arguments: %w", err)
+		}
+		in.Arguments = arguments
+	}
+	return in, nil
+}
+
+func argumentsJSON(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func equalJSON(a, b string) bool {
+	var left, right interface{}
+	if json.Unmarshal([]byte(a), &left) != nil || json.Unmarshal([]byte(b), &right) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// Report writes one line per case, followed by the diff of failed cases,
+// and returns the number of failures.
+func Report(w io.Writer, results []Result, verbose bool) int {
+	failed := 0
+	for _, r := range results {
+		if r.Passed {
+			if verbose {
+				fmt.Fprintf(w, "PASS  %s\n", caseName(r.Case))
+			}
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL  %s\n", caseName(r.Case))
+		for _, line := range r.Diff {
+			fmt.Fprintf(w, "      %s\n", line)
+		}
+		if r.Decision.Reason != "" {
+			fmt.Fprintf(w, "      reason: %s\n", r.Decision.Reason)
+		}
+	}
+	fmt.Fprintf(w, "%d passed, %d failed\n", len(results)-failed, failed)
+	return failed
+}
+
+func caseName(c Case) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	parts := []string{"model=" + c.Model}
+	if c.Tool != "" {
+		parts = append(parts, "tool="+c.Tool)
+	}
+	if c.Identity != "" {
+		parts = append(parts, "identity="+c.Identity)
+	}
+	return strings.Join(parts, " ")
+}
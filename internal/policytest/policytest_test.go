@@ -0,0 +1,135 @@
+package policytest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alereyleyva/agent-guard/internal/config"
+)
+
+func testConfig() config.Config {
+	return config.Config{
+		ModelAliases: map[string]string{"cheap-fast": "gpt-4o-mini"},
+		Policy: config.PolicyConfig{
+			IdentityHeader: "X-Caller",
+			Rules: []config.PolicyRule{
+				{ID: "no-shell-for-bots", Action: config.RuleActionDeny, Match: config.RuleMatch{Tools: []string{"shell_*"}, Callers: []string{"bot-*"}}},
+				{ID: "bounded-queries", Action: config.RuleActionRewrite, Match: config.RuleMatch{Tools: []string{"query_db"}}, Patches: []config.ArgumentPatch{{Op: config.PatchOpClamp, Field: "limit", Max: floatPtr(100)}}},
+				{ID: "night-freeze", Action: config.RuleActionDeny, Match: config.RuleMatch{Models: []string{"*"}, Time: &config.TimeWindow{Start: "22:00", End: "06:00"}}},
+			},
+			Models: config.ModelPolicy{Allow: []string{"gpt-4o-mini"}},
+			Tools:  config.ToolPolicy{Allow: []string{"*"}},
+		},
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		c        Case
+		wantPass bool
+		wantDiff string
+	}{
+		{"alias resolved", Case{Model: "cheap-fast", Time: "2024-05-01T12:00:00Z", Expect: Expect{Action: "allow", RuleID: "MODEL_ALLOW"}}, true, ""},
+		{"time window", Case{Model: "gpt-4o-mini", Time: "2024-05-01T23:00:00Z", Expect: Expect{Action: "deny", RuleID: "night-freeze"}}, true, ""},
+		{"identity", Case{Model: "gpt-4o-mini", Tool: "shell_exec", Identity: "bot-1", Expect: Expect{Action: "deny", RuleID: "no-shell-for-bots"}}, true, ""},
+		{"rewritten arguments", Case{Model: "gpt-4o-mini", Tool: "query_db", Arguments: map[string]interface{}{"limit": 500}, Expect: Expect{Action: "rewrite", Arguments: `{"limit": 100}`}}, true, ""},
+		{"wrong action", Case{Model: "gpt-4o-mini", Tool: "shell_exec", Identity: "ops", Expect: Expect{Action: "deny"}}, false, `action: want "deny", got "allow"`},
+		{"wrong rule", Case{Model: "gpt-4o-mini", Tool: "shell_exec", Identity: "bot-1", Expect: Expect{Action: "deny", RuleID: "other"}}, false, `rule_id: want "other", got "no-shell-for-bots"`},
+		{"wrong arguments", Case{Model: "gpt-4o-mini", Tool: "query_db", Arguments: `{"limit": 500}`, Expect: Expect{Action: "rewrite", Arguments: `{"limit": 50}`}}, false, "arguments: want"},
+		{"bad time", Case{Model: "gpt-4o-mini", Time: "tomorrow", Expect: Expect{Action: "allow"}}, false, "time:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Run(testConfig(), Suite{Cases: []Case{tt.c}})
+			if len(results) != 1 {
+				t.Fatalf("Run() returned %d results, want 1", len(results))
+			}
+			result := results[0]
+			if result.Passed != tt.wantPass {
+				t.Errorf("Passed = %v, want %v (diff %v)", result.Passed, tt.wantPass, result.Diff)
+			}
+			if tt.wantDiff != "" && !strings.Contains(strings.Join(result.Diff, "\n"), tt.wantDiff) {
+				t.Errorf("Diff = %v, want it to contain %q", result.Diff, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestLoadSuiteAndReport(t *testing.T) {
+	fixtures := `
+cases:
+  - name: "bots cannot shell"
+    model: "gpt-4o-mini"
+    tool: "shell_exec"
+    identity: "bot-1"
+    expect:
+      action: "deny"
+      rule_id: "no-shell-for-bots"
+  - name: "ops can shell"
+    model: "gpt-4o-mini"
+    tool: "shell_exec"
+    identity: "ops"
+    expect:
+      action: "deny"
+`
+	path := filepath.Join(t.TempDir(), "fixtures.yaml")
+	if err := os.WriteFile(path, []byte(fixtures), 0644); err != nil {
+		t.Fatalf("failed to write fixtures: %v", err)
+	}
+
+	suite, err := LoadSuite(path)
+	if err != nil {
+		t.Fatalf("LoadSuite() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	failed := Report(&out, Run(testConfig(), suite), true)
+	if failed != 1 {
+		t.Errorf("Report() = %d failures, want 1", failed)
+	}
+	for _, want := range []string{"PASS  bots cannot shell", "FAIL  ops can shell", `action: want "deny", got "allow"`, "1 passed, 1 failed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestLoadSuite_MissingExpectation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.yaml")
+	if err := os.WriteFile(path, []byte("cases:\n  - model: \"gpt-4o\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixtures: %v", err)
+	}
+
+	if _, err := LoadSuite(path); err == nil {
+		t.Error("LoadSuite() should return error when expect.action is missing")
+	}
+}
+
+func TestRun_ExampleOffline(t *testing.T) {
+	for _, name := range []string{"OPENAI_API_KEY", "OPENROUTER_API_KEY", "AGENTGUARD_ADMIN_TOKEN", "AGENTGUARD_PII_KEY"} {
+		t.Setenv(name, "")
+	}
+
+	cfg, err := config.LoadPolicy(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	suite, err := LoadSuite(filepath.Join("..", "..", "policy-tests.example.yaml"))
+	if err != nil {
+		t.Fatalf("LoadSuite() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if failed := Report(&out, Run(*cfg, suite), false); failed > 0 {
+		t.Errorf("example fixtures: %d failed\n%s", failed, out.String())
+	}
+}
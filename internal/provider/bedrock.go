@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -78,7 +79,10 @@ func (p *BedrockProvider) Name() string {
 }
 
 func (p *BedrockProvider) BuildUpstreamRequest(req normalize.NormalizedRequest) (*http.Request, error) {
-	bedrockReq := buildBedrockConverseRequest(req)
+	bedrockReq, err := buildBedrockConverseRequest(req)
+	if err != nil {
+		return nil, err
+	}
 	body, err := json.Marshal(bedrockReq)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
@@ -131,24 +135,48 @@ func (p *BedrockProvider) ParseUpstreamResponse(resp *http.Response) (normalize.
 	return normalized, nil
 }
 
-func buildBedrockConverseRequest(req normalize.NormalizedRequest) bedrockConverseRequest {
+// NativeResponses reports that Converse responses are not in Chat
+// Completions format.
+func (p *BedrockProvider) NativeResponses() bool {
+	return true
+}
+
+func (p *BedrockProvider) RewriteResponse(body []byte, edit normalize.ResponseEdit) ([]byte, error) {
+	if edit.IsEmpty() {
+		return body, nil
+	}
+	return rewriteBedrockConverseResponse(body, edit)
+}
+
+func buildBedrockConverseRequest(req normalize.NormalizedRequest) (bedrockConverseRequest, error) {
+	if err := checkBedrockOptions(req.RequestOptions); err != nil {
+		return bedrockConverseRequest{}, err
+	}
+
 	messages := make([]bedrockMessage, 0, len(req.Messages))
 	system := make([]bedrockContentBlock, 0)
 
 	for i, msg := range req.Messages {
+		if len(msg.Extra) > 0 {
+			names := make([]string, 0, len(msg.Extra))
+			for name := range msg.Extra {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return bedrockConverseRequest{}, fmt.Errorf("message %d: %w: bedrock does not accept %s", i, ErrUnsupportedParameter, strings.Join(names, ", "))
+		}
+
 		role := strings.ToLower(msg.Role)
 		if role == "system" {
-			if msg.Content != "" {
-				content := msg.Content
-				system = append(system, bedrockContentBlock{Text: &content})
+			if text := msg.Text(); text != "" {
+				system = append(system, bedrockContentBlock{Text: &text})
 			}
 			continue
 		}
 
-		contentBlocks := make([]bedrockContentBlock, 0)
-		if msg.Content != "" {
-			content := msg.Content
-			contentBlocks = append(contentBlocks, bedrockContentBlock{Text: &content})
+		contentBlocks, err := bedrockMessageContent(msg)
+		if err != nil {
+			return bedrockConverseRequest{}, fmt.Errorf("message %d: %w", i, err)
 		}
 
 		if role == "assistant" {
@@ -174,16 +202,11 @@ func buildBedrockConverseRequest(req normalize.NormalizedRequest) bedrockConvers
 			if toolID == "" {
 				toolID = fmt.Sprintf("toolcall-%d", i)
 			}
-			toolContent := make([]bedrockContentBlock, 0)
-			if msg.Content != "" {
-				content := msg.Content
-				toolContent = append(toolContent, bedrockContentBlock{Text: &content})
-			}
 			contentBlocks = []bedrockContentBlock{
 				{
 					ToolResult: &bedrockToolResult{
 						ToolUseID: toolID,
-						Content:   toolContent,
+						Content:   contentBlocks,
 					},
 				},
 			}
@@ -218,11 +241,136 @@ func buildBedrockConverseRequest(req normalize.NormalizedRequest) bedrockConvers
 			toolConfig = &bedrockToolConfig{Tools: tools}
 		}
 	}
+	if toolConfig != nil && req.ToolChoice != nil {
+		// Converse has no "none" choice; the tools are left out instead.
+		// A conversation that already contains tool blocks needs a tool
+		// config, which would let the model call tools again.
+		if req.ToolChoice.Mode == normalize.ToolChoiceNone {
+			if len(req.ToolCallHistory()) > 0 {
+				return bedrockConverseRequest{}, fmt.Errorf("%w: bedrock cannot honor tool_choice \"none\" once the conversation contains tool calls", ErrUnsupportedParameter)
+			}
+			toolConfig = nil
+		} else {
+			toolConfig.ToolChoice = bedrockToolChoiceFor(*req.ToolChoice)
+		}
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens == nil {
+		maxTokens = req.MaxCompletionTokens
+	}
+	var inferenceConfig *bedrockInferenceConfig
+	if maxTokens != nil || req.Temperature != nil || req.TopP != nil || len(req.Stop) > 0 {
+		inferenceConfig = &bedrockInferenceConfig{
+			MaxTokens:     maxTokens,
+			Temperature:   req.Temperature,
+			TopP:          req.TopP,
+			StopSequences: req.Stop,
+		}
+	}
 
 	return bedrockConverseRequest{
-		Messages:   messages,
-		System:     system,
-		ToolConfig: toolConfig,
+		Messages:                     messages,
+		System:                       system,
+		InferenceConfig:              inferenceConfig,
+		ToolConfig:                   toolConfig,
+		AdditionalModelRequestFields: req.Extra,
+	}, nil
+}
+
+// checkBedrockOptions rejects OpenAI parameters that Converse has no field
+// for, rather than dropping them and changing what the client asked for.
+func checkBedrockOptions(opts normalize.RequestOptions) error {
+	var unsupported string
+	switch {
+	case opts.N != nil && *opts.N != 1:
+		unsupported = "n"
+	case opts.Seed != nil:
+		unsupported = "seed"
+	case opts.ResponseFormat != nil && opts.ResponseFormat.Type != "text":
+		unsupported = "response_format"
+	case opts.Logprobs != nil && *opts.Logprobs:
+		unsupported = "logprobs"
+	case opts.TopLogprobs != nil:
+		unsupported = "top_logprobs"
+	case len(opts.LogitBias) > 0:
+		unsupported = "logit_bias"
+	case opts.PresencePenalty != nil:
+		unsupported = "presence_penalty"
+	case opts.FrequencyPenalty != nil:
+		unsupported = "frequency_penalty"
+	case opts.User != "":
+		unsupported = "user"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%w: bedrock does not accept %s", ErrUnsupportedParameter, unsupported)
+}
+
+// bedrockMessageContent maps message text and image parts to Converse
+// content blocks. Converse only takes inline images, so remote image URLs,
+// audio and file parts are rejected.
+func bedrockMessageContent(msg normalize.Message) ([]bedrockContentBlock, error) {
+	blocks := make([]bedrockContentBlock, 0)
+	if msg.Parts == nil {
+		if msg.Content != "" {
+			content := msg.Content
+			blocks = append(blocks, bedrockContentBlock{Text: &content})
+		}
+		return blocks, nil
+	}
+
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case normalize.ContentPartText:
+			if part.Text != "" {
+				text := part.Text
+				blocks = append(blocks, bedrockContentBlock{Text: &text})
+			}
+		case normalize.ContentPartRefusal:
+			if part.Refusal != "" {
+				refusal := part.Refusal
+				blocks = append(blocks, bedrockContentBlock{Text: &refusal})
+			}
+		case normalize.ContentPartImageURL:
+			image, err := bedrockImageFor(*part.ImageURL)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, bedrockContentBlock{Image: image})
+		default:
+			return nil, fmt.Errorf("%w: bedrock does not accept %s content parts", ErrUnsupportedContent, part.Type)
+		}
+	}
+	return blocks, nil
+}
+
+func bedrockImageFor(image normalize.ImageURL) (*bedrockImage, error) {
+	mediaType, data, ok := image.DataURL()
+	if !ok {
+		return nil, fmt.Errorf("%w: bedrock only accepts images as base64 data URLs", ErrUnsupportedContent)
+	}
+	format := strings.TrimPrefix(mediaType, "image/")
+	switch format {
+	case "png", "jpeg", "gif", "webp":
+	case "jpg":
+		format = "jpeg"
+	default:
+		return nil, fmt.Errorf("%w: bedrock does not accept %q images", ErrUnsupportedContent, mediaType)
+	}
+	return &bedrockImage{Format: format, Source: bedrockImageSource{Bytes: data}}, nil
+}
+
+func bedrockToolChoiceFor(choice normalize.ToolChoice) *bedrockToolChoice {
+	switch {
+	case choice.Function != "":
+		return &bedrockToolChoice{Tool: &bedrockToolChoiceName{Name: choice.Function}}
+	case choice.Mode == normalize.ToolChoiceRequired:
+		return &bedrockToolChoice{Any: &struct{}{}}
+	case choice.Mode == normalize.ToolChoiceAuto:
+		return &bedrockToolChoice{Auto: &struct{}{}}
+	default:
+		return nil
 	}
 }
 
@@ -263,9 +411,129 @@ func parseBedrockConverseResponse(body []byte) (normalize.NormalizedResponse, er
 	}
 
 	normalized.Content = contentBuilder.String()
+	normalized.Contents = []string{normalized.Content}
+	normalized.FinishReason = bedrockFinishReason(resp.StopReason)
+	if resp.Usage != nil {
+		normalized.Usage = &normalize.Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+	}
 	return normalized, nil
 }
 
+// bedrockFinishReason maps a Converse stopReason to a Chat Completions
+// finish_reason.
+func bedrockFinishReason(stopReason string) string {
+	switch stopReason {
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	case "content_filtered", "guardrail_intervened":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+func rewriteBedrockConverseResponse(body []byte, edit normalize.ResponseEdit) ([]byte, error) {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	var output map[string]json.RawMessage
+	if err := json.Unmarshal(resp["output"], &output); err != nil || output == nil {
+		return nil, fmt.Errorf("missing output message")
+	}
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(output["message"], &message); err != nil || message == nil {
+		return nil, fmt.Errorf("missing output message")
+	}
+	var blocks []map[string]json.RawMessage
+	if err := json.Unmarshal(message["content"], &blocks); err != nil {
+		return nil, fmt.Errorf("parsing content blocks: %w", err)
+	}
+
+	kept := make([]map[string]json.RawMessage, 0, len(blocks))
+	notices := make([]string, 0)
+	toolIndex := 0
+	remainingToolUses := 0
+	for _, block := range blocks {
+		if _, isToolUse := block["toolUse"]; isToolUse {
+			notice, drop := edit.DropToolCalls[toolIndex]
+			rewrite := edit.ArgumentsRewrite(toolIndex)
+			toolIndex++
+			if drop {
+				if notice != "" {
+					notices = append(notices, notice)
+				}
+				continue
+			}
+			remainingToolUses++
+			if rewrite != nil {
+				if err := rewriteBedrockToolInput(block, rewrite); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if raw, isText := block["text"]; isText && edit.Content != nil {
+			var text string
+			if err := json.Unmarshal(raw, &text); err == nil {
+				if err := setRaw(block, "text", edit.Content(text)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		kept = append(kept, block)
+	}
+
+	if len(notices) > 0 {
+		text := appendNotices("", notices)
+		textBlock := map[string]json.RawMessage{}
+		if err := setRaw(textBlock, "text", text); err != nil {
+			return nil, err
+		}
+		kept = append(kept, textBlock)
+	}
+	if remainingToolUses == 0 && toolIndex > 0 {
+		var stopReason string
+		_ = json.Unmarshal(resp["stopReason"], &stopReason)
+		if stopReason == "tool_use" {
+			resp["stopReason"] = json.RawMessage(`"end_turn"`)
+		}
+	}
+
+	if err := setRaw(message, "content", kept); err != nil {
+		return nil, err
+	}
+	if err := setRaw(output, "message", message); err != nil {
+		return nil, err
+	}
+	if err := setRaw(resp, "output", output); err != nil {
+		return nil, err
+	}
+	return json.Marshal(resp)
+}
+
+func rewriteBedrockToolInput(block map[string]json.RawMessage, rewrite func(string) string) error {
+	var toolUse map[string]json.RawMessage
+	if err := json.Unmarshal(block["toolUse"], &toolUse); err != nil || toolUse == nil {
+		return nil
+	}
+	input := "{}"
+	if raw, ok := toolUse["input"]; ok {
+		input = string(raw)
+	}
+	rewritten := rewrite(input)
+	if !json.Valid([]byte(rewritten)) {
+		return fmt.Errorf("rewritten tool input is not valid JSON")
+	}
+	toolUse["input"] = json.RawMessage(rewritten)
+	return setRaw(block, "toolUse", toolUse)
+}
+
 func parseToolArguments(args string) interface{} {
 	if args == "" {
 		return map[string]interface{}{}
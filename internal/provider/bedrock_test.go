@@ -3,6 +3,7 @@ package provider
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -17,6 +18,7 @@ func TestBedrockProvider_BuildUpstreamRequest(t *testing.T) {
 		t.Fatalf("NewBedrock() error = %v", err)
 	}
 
+	maxTokens := 512
 	req := normalize.NormalizedRequest{
 		Model: "anthropic.claude-3-5-sonnet-20240620-v1:0",
 		Messages: []normalize.Message{
@@ -27,7 +29,8 @@ func TestBedrockProvider_BuildUpstreamRequest(t *testing.T) {
 			}},
 			{Role: "tool", ToolCallID: "call-1", Content: "result"},
 		},
-		Tools: []normalize.Tool{{Type: "function", Function: normalize.ToolFunction{Name: "search_web"}}},
+		Tools:          []normalize.Tool{{Type: "function", Function: normalize.ToolFunction{Name: "search_web"}}},
+		RequestOptions: normalize.RequestOptions{MaxTokens: &maxTokens},
 	}
 
 	httpReq, err := p.BuildUpstreamRequest(req)
@@ -63,6 +66,185 @@ func TestBedrockProvider_BuildUpstreamRequest(t *testing.T) {
 	if decoded["toolConfig"] == nil {
 		t.Errorf("toolConfig should be present")
 	}
+	inference, _ := decoded["inferenceConfig"].(map[string]interface{})
+	if inference["maxTokens"] != float64(512) {
+		t.Errorf("inferenceConfig = %#v, want maxTokens 512", decoded["inferenceConfig"])
+	}
+}
+
+func TestBuildBedrockConverseRequest_RequestOptions(t *testing.T) {
+	maxTokens := 128
+	tools := []normalize.Tool{{Type: "function", Function: normalize.ToolFunction{Name: "search_web"}}}
+	toolHistory := []normalize.Message{
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", ToolCalls: []normalize.ToolCall{
+			{ID: "call-1", Type: "function", Function: normalize.FunctionCall{Name: "search_web", Arguments: `{}`}},
+		}},
+		{Role: "tool", ToolCallID: "call-1", Content: "result"},
+	}
+
+	tests := []struct {
+		name       string
+		messages   []normalize.Message
+		choice     *normalize.ToolChoice
+		wantConfig bool
+		wantChoice string
+	}{
+		{name: "auto", choice: &normalize.ToolChoice{Mode: normalize.ToolChoiceAuto}, wantConfig: true, wantChoice: `{"auto":{}}`},
+		{name: "required", choice: &normalize.ToolChoice{Mode: normalize.ToolChoiceRequired}, wantConfig: true, wantChoice: `{"any":{}}`},
+		{name: "named", choice: &normalize.ToolChoice{Function: "search_web"}, wantConfig: true, wantChoice: `{"tool":{"name":"search_web"}}`},
+		{name: "none drops tools", choice: &normalize.ToolChoice{Mode: normalize.ToolChoiceNone}},
+		{name: "auto with history", messages: toolHistory, choice: &normalize.ToolChoice{Mode: normalize.ToolChoiceAuto}, wantConfig: true, wantChoice: `{"auto":{}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := tt.messages
+			if messages == nil {
+				messages = []normalize.Message{{Role: "user", Content: "hello"}}
+			}
+			converse, err := buildBedrockConverseRequest(normalize.NormalizedRequest{
+				Model:          "anthropic.claude-3-5-sonnet-20240620-v1:0",
+				Messages:       messages,
+				Tools:          tools,
+				RequestOptions: normalize.RequestOptions{ToolChoice: tt.choice},
+			})
+			if err != nil {
+				t.Fatalf("buildBedrockConverseRequest() error = %v", err)
+			}
+
+			if (converse.ToolConfig != nil) != tt.wantConfig {
+				t.Fatalf("ToolConfig = %#v, want present %v", converse.ToolConfig, tt.wantConfig)
+			}
+			if converse.ToolConfig == nil {
+				return
+			}
+			got := ""
+			if converse.ToolConfig.ToolChoice != nil {
+				data, _ := json.Marshal(converse.ToolConfig.ToolChoice)
+				got = string(data)
+			}
+			if got != tt.wantChoice {
+				t.Errorf("ToolChoice = %s, want %s", got, tt.wantChoice)
+			}
+		})
+	}
+
+	converse, err := buildBedrockConverseRequest(normalize.NormalizedRequest{
+		Model: "anthropic.claude-3-5-sonnet-20240620-v1:0",
+		RequestOptions: normalize.RequestOptions{
+			MaxCompletionTokens: &maxTokens,
+			Stop:                normalize.Stop{"END"},
+		},
+		Extra: map[string]json.RawMessage{"top_k": json.RawMessage(`40`)},
+	})
+	if err != nil {
+		t.Fatalf("buildBedrockConverseRequest() error = %v", err)
+	}
+	if converse.InferenceConfig == nil || converse.InferenceConfig.MaxTokens == nil || *converse.InferenceConfig.MaxTokens != 128 {
+		t.Errorf("InferenceConfig = %#v, want maxTokens 128", converse.InferenceConfig)
+	} else if len(converse.InferenceConfig.StopSequences) != 1 || converse.InferenceConfig.StopSequences[0] != "END" {
+		t.Errorf("StopSequences = %v, want [END]", converse.InferenceConfig.StopSequences)
+	}
+	if string(converse.AdditionalModelRequestFields["top_k"]) != "40" {
+		t.Errorf("AdditionalModelRequestFields = %v, want top_k", converse.AdditionalModelRequestFields)
+	}
+}
+
+func TestBuildBedrockConverseRequest_UnsupportedParameters(t *testing.T) {
+	one, two := 1, 2
+	seed := int64(7)
+	penalty := 0.5
+	enabled := true
+
+	tests := []struct {
+		name    string
+		opts    normalize.RequestOptions
+		wantErr bool
+	}{
+		{"n of one", normalize.RequestOptions{N: &one}, false},
+		{"text response format", normalize.RequestOptions{ResponseFormat: &normalize.ResponseFormat{Type: "text"}}, false},
+		{"n", normalize.RequestOptions{N: &two}, true},
+		{"seed", normalize.RequestOptions{Seed: &seed}, true},
+		{"json response format", normalize.RequestOptions{ResponseFormat: &normalize.ResponseFormat{Type: "json_object"}}, true},
+		{"logprobs", normalize.RequestOptions{Logprobs: &enabled}, true},
+		{"top logprobs", normalize.RequestOptions{TopLogprobs: &two}, true},
+		{"logit bias", normalize.RequestOptions{LogitBias: map[string]float64{"50256": -100}}, true},
+		{"presence penalty", normalize.RequestOptions{PresencePenalty: &penalty}, true},
+		{"frequency penalty", normalize.RequestOptions{FrequencyPenalty: &penalty}, true},
+		{"user", normalize.RequestOptions{User: "user-123"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildBedrockConverseRequest(normalize.NormalizedRequest{
+				Messages:       []normalize.Message{{Role: "user", Content: "hello"}},
+				RequestOptions: tt.opts,
+			})
+			if tt.wantErr && !errors.Is(err, ErrUnsupportedParameter) {
+				t.Errorf("buildBedrockConverseRequest() error = %v, want ErrUnsupportedParameter", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("buildBedrockConverseRequest() error = %v", err)
+			}
+		})
+	}
+
+	_, err := buildBedrockConverseRequest(normalize.NormalizedRequest{
+		Messages: []normalize.Message{
+			{Role: "user", Content: "hello"},
+			{Role: "assistant", ToolCalls: []normalize.ToolCall{
+				{ID: "call-1", Type: "function", Function: normalize.FunctionCall{Name: "search_web", Arguments: `{}`}},
+			}},
+			{Role: "tool", ToolCallID: "call-1", Content: "result"},
+		},
+		Tools:          []normalize.Tool{{Type: "function", Function: normalize.ToolFunction{Name: "search_web"}}},
+		RequestOptions: normalize.RequestOptions{ToolChoice: &normalize.ToolChoice{Mode: normalize.ToolChoiceNone}},
+	})
+	if !errors.Is(err, ErrUnsupportedParameter) {
+		t.Errorf("tool_choice none with tool history error = %v, want ErrUnsupportedParameter", err)
+	}
+
+	_, err = buildBedrockConverseRequest(normalize.NormalizedRequest{
+		Messages: []normalize.Message{{Role: "user", Content: "hello", Extra: map[string]json.RawMessage{"audio": json.RawMessage(`{"id":"audio-1"}`)}}},
+	})
+	if !errors.Is(err, ErrUnsupportedParameter) {
+		t.Errorf("message field error = %v, want ErrUnsupportedParameter", err)
+	}
+}
+
+func TestBuildBedrockConverseRequest_ContentParts(t *testing.T) {
+	image := normalize.ContentPart{Type: normalize.ContentPartImageURL, ImageURL: &normalize.ImageURL{URL: "data:image/jpg;base64,aGVsbG8="}}
+	converse, err := buildBedrockConverseRequest(normalize.NormalizedRequest{
+		Messages: []normalize.Message{{Role: "user", Parts: []normalize.ContentPart{
+			{Type: normalize.ContentPartText, Text: "what is this?"},
+			image,
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("buildBedrockConverseRequest() error = %v", err)
+	}
+	blocks := converse.Messages[0].Content
+	if len(blocks) != 2 || blocks[0].Text == nil || *blocks[0].Text != "what is this?" {
+		t.Fatalf("Content = %#v, want text then image", blocks)
+	}
+	if img := blocks[1].Image; img == nil || img.Format != "jpeg" || img.Source.Bytes != "aGVsbG8=" {
+		t.Errorf("Image = %#v, want jpeg with the data URL bytes", blocks[1].Image)
+	}
+
+	unsupported := []normalize.ContentPart{
+		{Type: normalize.ContentPartImageURL, ImageURL: &normalize.ImageURL{URL: "https://example.com/cat.png"}},
+		{Type: normalize.ContentPartImageURL, ImageURL: &normalize.ImageURL{URL: "data:image/bmp;base64,aGVsbG8="}},
+		{Type: normalize.ContentPartInputAudio, InputAudio: &normalize.InputAudio{Data: "aGVsbG8=", Format: "wav"}},
+	}
+	for _, part := range unsupported {
+		_, err := buildBedrockConverseRequest(normalize.NormalizedRequest{
+			Messages: []normalize.Message{{Role: "user", Parts: []normalize.ContentPart{part}}},
+		})
+		if !errors.Is(err, ErrUnsupportedContent) {
+			t.Errorf("buildBedrockConverseRequest(%s) error = %v, want ErrUnsupportedContent", part.Type, err)
+		}
+	}
 }
 
 func TestBedrockProvider_BuildUpstreamRequest_Stream(t *testing.T) {
@@ -82,7 +264,7 @@ func TestBedrockProvider_BuildUpstreamRequest_Stream(t *testing.T) {
 }
 
 func TestBedrockProvider_ParseUpstreamResponse(t *testing.T) {
-	payload := `{"output":{"message":{"role":"assistant","content":[{"text":"hello"},{"toolUse":{"toolUseId":"call-1","name":"search_web","input":{"q":"hi"}}}]}}}`
+	payload := `{"output":{"message":{"role":"assistant","content":[{"text":"hello"},{"toolUse":{"toolUseId":"call-1","name":"search_web","input":{"q":"hi"}}}]}},"stopReason":"tool_use","usage":{"inputTokens":12,"outputTokens":5,"totalTokens":17}}`
 	resp := &http.Response{Body: io.NopCloser(bytes.NewBufferString(payload))}
 
 	p, err := NewBedrock("us-east-1", "https://bedrock-runtime.us-east-1.amazonaws.com", "test", "secret", "")
@@ -101,4 +283,55 @@ func TestBedrockProvider_ParseUpstreamResponse(t *testing.T) {
 	if len(normalized.ToolCalls) != 1 || normalized.ToolCalls[0].Function.Name != "search_web" {
 		t.Errorf("ToolCalls = %#v, want one tool call named search_web", normalized.ToolCalls)
 	}
+	if normalized.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", normalized.FinishReason)
+	}
+	if normalized.Usage == nil || *normalized.Usage != (normalize.Usage{PromptTokens: 12, CompletionTokens: 5, TotalTokens: 17}) {
+		t.Errorf("Usage = %#v, want 12/5/17", normalized.Usage)
+	}
+}
+
+func TestBedrockFinishReason(t *testing.T) {
+	tests := map[string]string{
+		"end_turn":             "stop",
+		"stop_sequence":        "stop",
+		"max_tokens":           "length",
+		"tool_use":             "tool_calls",
+		"content_filtered":     "content_filter",
+		"guardrail_intervened": "content_filter",
+		"":                     "stop",
+	}
+	for stopReason, want := range tests {
+		if got := bedrockFinishReason(stopReason); got != want {
+			t.Errorf("bedrockFinishReason(%q) = %q, want %q", stopReason, got, want)
+		}
+	}
+}
+
+func TestBedrockProvider_RewriteResponse(t *testing.T) {
+	payload := `{"output":{"message":{"role":"assistant","content":[{"toolUse":{"toolUseId":"call-1","name":"shell_exec","input":{}}}]}},"stopReason":"tool_use"}`
+
+	p, err := NewBedrock("us-east-1", "https://bedrock-runtime.us-east-1.amazonaws.com", "test", "secret", "")
+	if err != nil {
+		t.Fatalf("NewBedrock() error = %v", err)
+	}
+
+	body, err := p.RewriteResponse([]byte(payload), normalize.ResponseEdit{DropToolCalls: map[int]string{0: "blocked"}})
+	if err != nil {
+		t.Fatalf("RewriteResponse() error = %v", err)
+	}
+
+	normalized, err := parseBedrockConverseResponse(body)
+	if err != nil {
+		t.Fatalf("parseBedrockConverseResponse() error = %v", err)
+	}
+	if len(normalized.ToolCalls) != 0 {
+		t.Errorf("ToolCalls = %#v, want none", normalized.ToolCalls)
+	}
+	if normalized.Content != "blocked" {
+		t.Errorf("Content = %q, want %q", normalized.Content, "blocked")
+	}
+	if !strings.Contains(string(body), `"stopReason":"end_turn"`) {
+		t.Errorf("body = %s, want stopReason end_turn", body)
+	}
 }
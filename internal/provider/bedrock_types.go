@@ -1,9 +1,20 @@
 package provider
 
+import "encoding/json"
+
 type bedrockConverseRequest struct {
-	Messages   []bedrockMessage      `json:"messages,omitempty"`
-	System     []bedrockContentBlock `json:"system,omitempty"`
-	ToolConfig *bedrockToolConfig    `json:"toolConfig,omitempty"`
+	Messages                     []bedrockMessage           `json:"messages,omitempty"`
+	System                       []bedrockContentBlock      `json:"system,omitempty"`
+	InferenceConfig              *bedrockInferenceConfig    `json:"inferenceConfig,omitempty"`
+	ToolConfig                   *bedrockToolConfig         `json:"toolConfig,omitempty"`
+	AdditionalModelRequestFields map[string]json.RawMessage `json:"additionalModelRequestFields,omitempty"`
+}
+
+type bedrockInferenceConfig struct {
+	MaxTokens     *int     `json:"maxTokens,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
 }
 
 type bedrockMessage struct {
@@ -13,10 +24,22 @@ type bedrockMessage struct {
 
 type bedrockContentBlock struct {
 	Text       *string            `json:"text,omitempty"`
+	Image      *bedrockImage      `json:"image,omitempty"`
 	ToolUse    *bedrockToolUse    `json:"toolUse,omitempty"`
 	ToolResult *bedrockToolResult `json:"toolResult,omitempty"`
 }
 
+type bedrockImage struct {
+	Format string             `json:"format"`
+	Source bedrockImageSource `json:"source"`
+}
+
+// bedrockImageSource carries the image bytes base64 encoded, as they
+// arrive in the data URL.
+type bedrockImageSource struct {
+	Bytes string `json:"bytes"`
+}
+
 type bedrockToolUse struct {
 	ToolUseID string      `json:"toolUseId"`
 	Name      string      `json:"name"`
@@ -30,7 +53,18 @@ type bedrockToolResult struct {
 }
 
 type bedrockToolConfig struct {
-	Tools []bedrockTool `json:"tools"`
+	Tools      []bedrockTool      `json:"tools"`
+	ToolChoice *bedrockToolChoice `json:"toolChoice,omitempty"`
+}
+
+type bedrockToolChoice struct {
+	Auto *struct{}              `json:"auto,omitempty"`
+	Any  *struct{}              `json:"any,omitempty"`
+	Tool *bedrockToolChoiceName `json:"tool,omitempty"`
+}
+
+type bedrockToolChoiceName struct {
+	Name string `json:"name"`
 }
 
 type bedrockTool struct {
@@ -51,4 +85,12 @@ type bedrockConverseResponse struct {
 	Output struct {
 		Message bedrockMessage `json:"message"`
 	} `json:"output"`
+	StopReason string        `json:"stopReason"`
+	Usage      *bedrockUsage `json:"usage"`
+}
+
+type bedrockUsage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+	TotalTokens  int `json:"totalTokens"`
 }
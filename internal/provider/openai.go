@@ -2,7 +2,6 @@ package provider
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -42,14 +41,7 @@ func (p *OpenAIProvider) Name() string {
 }
 
 func (p *OpenAIProvider) BuildUpstreamRequest(req normalize.NormalizedRequest) (*http.Request, error) {
-	openAIReq := openAIRequest{
-		Model:    req.Model,
-		Messages: req.Messages,
-		Stream:   req.Stream,
-		Tools:    req.Tools,
-	}
-
-	body, err := json.Marshal(openAIReq)
+	body, err := marshalOpenAIRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
@@ -76,3 +68,10 @@ func (p *OpenAIProvider) ParseUpstreamResponse(resp *http.Response) (normalize.N
 
 	return parseOpenAIResponse(body)
 }
+
+func (p *OpenAIProvider) RewriteResponse(body []byte, edit normalize.ResponseEdit) ([]byte, error) {
+	if edit.IsEmpty() {
+		return body, nil
+	}
+	return rewriteOpenAIResponse(body, edit)
+}
@@ -54,8 +54,56 @@ func TestOpenAIProvider_BuildUpstreamRequest(t *testing.T) {
 	}
 }
 
+func TestOpenAIProvider_BuildUpstreamRequest_Passthrough(t *testing.T) {
+	p := NewOpenAI("https://api.openai.com/", "test-key")
+	seed := int64(7)
+	req := normalize.NormalizedRequest{
+		Model:    "gpt-4o",
+		Messages: []normalize.Message{{Role: "user", Content: "hello"}},
+		RequestOptions: normalize.RequestOptions{
+			ToolChoice: &normalize.ToolChoice{Function: "search_web"},
+			Stop:       normalize.Stop{"END"},
+			Seed:       &seed,
+		},
+		Extra: map[string]json.RawMessage{
+			"prediction": json.RawMessage(`{"type":"content"}`),
+			"model":      json.RawMessage(`"other"`),
+		},
+	}
+
+	httpReq, err := p.BuildUpstreamRequest(req)
+	if err != nil {
+		t.Fatalf("BuildUpstreamRequest() error = %v", err)
+	}
+	body, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		t.Fatalf("reading body error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("unmarshal request body error = %v", err)
+	}
+	if decoded["model"] != "gpt-4o" {
+		t.Errorf("model = %v, want gpt-4o", decoded["model"])
+	}
+	if decoded["seed"] != float64(7) {
+		t.Errorf("seed = %v, want 7", decoded["seed"])
+	}
+	if stop, _ := decoded["stop"].([]interface{}); len(stop) != 1 || stop[0] != "END" {
+		t.Errorf("stop = %v, want [END]", decoded["stop"])
+	}
+	choice, _ := decoded["tool_choice"].(map[string]interface{})
+	if function, _ := choice["function"].(map[string]interface{}); function["name"] != "search_web" {
+		t.Errorf("tool_choice = %v, want function search_web", decoded["tool_choice"])
+	}
+	if prediction, _ := decoded["prediction"].(map[string]interface{}); prediction["type"] != "content" {
+		t.Errorf("prediction = %v, want passthrough", decoded["prediction"])
+	}
+}
+
 func TestOpenAIProvider_ParseUpstreamResponse(t *testing.T) {
-	payload := `{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hello","tool_calls":[{"id":"call-1","type":"function","function":{"name":"search_web","arguments":"{}"}}]},"finish_reason":"stop"}]}`
+	payload := `{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hello","tool_calls":[{"id":"call-1","type":"function","function":{"name":"search_web","arguments":"{}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":9,"completion_tokens":3,"total_tokens":12}}`
 	resp := &http.Response{Body: io.NopCloser(bytes.NewBufferString(payload))}
 
 	normalized, err := NewOpenAI("https://api.openai.com", "").ParseUpstreamResponse(resp)
@@ -75,6 +123,12 @@ func TestOpenAIProvider_ParseUpstreamResponse(t *testing.T) {
 	if len(normalized.ToolCalls) != 1 || normalized.ToolCalls[0].Function.Name != "search_web" {
 		t.Errorf("ToolCalls = %#v, want one tool call named search_web", normalized.ToolCalls)
 	}
+	if normalized.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", normalized.FinishReason)
+	}
+	if normalized.Usage == nil || normalized.Usage.TotalTokens != 12 {
+		t.Errorf("Usage = %#v, want total 12", normalized.Usage)
+	}
 }
 
 func TestOpenAIProvider_ParseUpstreamResponse_InvalidJSON(t *testing.T) {
@@ -3,6 +3,7 @@ package provider
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/alereyleyva/agent-guard/internal/normalize"
 )
@@ -12,6 +13,34 @@ type openAIRequest struct {
 	Messages []normalize.Message `json:"messages"`
 	Stream   bool                `json:"stream,omitempty"`
 	Tools    []normalize.Tool    `json:"tools,omitempty"`
+	normalize.RequestOptions
+}
+
+// marshalOpenAIRequest encodes req for an OpenAI-compatible upstream,
+// including the passthrough fields in req.Extra. Modeled fields win over
+// extra fields of the same name.
+func marshalOpenAIRequest(req normalize.NormalizedRequest) ([]byte, error) {
+	body, err := json.Marshal(openAIRequest{
+		Model:          req.Model,
+		Messages:       req.Messages,
+		Stream:         req.Stream,
+		Tools:          req.Tools,
+		RequestOptions: req.RequestOptions,
+	})
+	if err != nil || len(req.Extra) == 0 {
+		return body, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range req.Extra {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
 }
 
 type openAIResponse struct {
@@ -28,11 +57,7 @@ type openAIResponse struct {
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage *normalize.Usage `json:"usage"`
 }
 
 func parseOpenAIResponse(body []byte) (normalize.NormalizedResponse, error) {
@@ -45,12 +70,17 @@ func parseOpenAIResponse(body []byte) (normalize.NormalizedResponse, error) {
 		RawBody: body,
 		ID:      openAIResp.ID,
 		Model:   openAIResp.Model,
+		Usage:   openAIResp.Usage,
 	}
 
 	for _, choice := range openAIResp.Choices {
+		normalized.Contents = append(normalized.Contents, choice.Message.Content)
 		if normalized.Content == "" {
 			normalized.Content = choice.Message.Content
 		}
+		if normalized.FinishReason == "" {
+			normalized.FinishReason = choice.FinishReason
+		}
 		if len(choice.Message.ToolCalls) > 0 {
 			normalized.ToolCalls = append(normalized.ToolCalls, choice.Message.ToolCalls...)
 		}
@@ -58,3 +88,130 @@ func parseOpenAIResponse(body []byte) (normalize.NormalizedResponse, error) {
 
 	return normalized, nil
 }
+
+func rewriteOpenAIResponse(body []byte, edit normalize.ResponseEdit) ([]byte, error) {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	var choices []map[string]json.RawMessage
+	if raw, ok := resp["choices"]; ok {
+		if err := json.Unmarshal(raw, &choices); err != nil {
+			return nil, fmt.Errorf("parsing choices: %w", err)
+		}
+	}
+
+	toolIndex := 0
+	for _, choice := range choices {
+		var message map[string]json.RawMessage
+		if err := json.Unmarshal(choice["message"], &message); err != nil || message == nil {
+			continue
+		}
+
+		var toolCalls []json.RawMessage
+		if raw, ok := message["tool_calls"]; ok {
+			if err := json.Unmarshal(raw, &toolCalls); err != nil {
+				return nil, fmt.Errorf("parsing tool calls: %w", err)
+			}
+		}
+
+		kept := make([]json.RawMessage, 0, len(toolCalls))
+		notices := make([]string, 0)
+		rewrote := false
+		for _, toolCall := range toolCalls {
+			if notice, drop := edit.DropToolCalls[toolIndex]; drop {
+				if notice != "" {
+					notices = append(notices, notice)
+				}
+			} else {
+				if rewrite := edit.ArgumentsRewrite(toolIndex); rewrite != nil {
+					rewritten, err := rewriteOpenAIArguments(toolCall, rewrite)
+					if err != nil {
+						return nil, err
+					}
+					toolCall = rewritten
+					rewrote = true
+				}
+				kept = append(kept, toolCall)
+			}
+			toolIndex++
+		}
+		if len(kept) == len(toolCalls) && edit.Content == nil && !rewrote {
+			continue
+		}
+
+		if len(kept) != len(toolCalls) || rewrote {
+			if len(kept) == 0 {
+				delete(message, "tool_calls")
+				choice["finish_reason"] = json.RawMessage(`"stop"`)
+			} else if err := setRaw(message, "tool_calls", kept); err != nil {
+				return nil, err
+			}
+		}
+
+		var content string
+		hasContent := json.Unmarshal(message["content"], &content) == nil && content != ""
+		if hasContent && edit.Content != nil {
+			content = edit.Content(content)
+		}
+		if len(notices) > 0 {
+			content = appendNotices(content, notices)
+		}
+		if hasContent || len(notices) > 0 {
+			if err := setRaw(message, "content", content); err != nil {
+				return nil, err
+			}
+		}
+		if err := setRaw(choice, "message", message); err != nil {
+			return nil, err
+		}
+	}
+
+	if choices != nil {
+		if err := setRaw(resp, "choices", choices); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(resp)
+}
+
+func rewriteOpenAIArguments(toolCall json.RawMessage, rewrite func(string) string) (json.RawMessage, error) {
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(toolCall, &call); err != nil {
+		return nil, fmt.Errorf("parsing tool call: %w", err)
+	}
+	var function map[string]json.RawMessage
+	if err := json.Unmarshal(call["function"], &function); err != nil || function == nil {
+		return toolCall, nil
+	}
+	var arguments string
+	if err := json.Unmarshal(function["arguments"], &arguments); err != nil {
+		return toolCall, nil
+	}
+	if err := setRaw(function, "arguments", rewrite(arguments)); err != nil {
+		return nil, err
+	}
+	if err := setRaw(call, "function", function); err != nil {
+		return nil, err
+	}
+	return json.Marshal(call)
+}
+
+func appendNotices(content string, notices []string) string {
+	notice := strings.Join(notices, "\n")
+	if content == "" {
+		return notice
+	}
+	return content + "\n\n" + notice
+}
+
+func setRaw(obj map[string]json.RawMessage, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", key, err)
+	}
+	obj[key] = data
+	return nil
+}
@@ -2,7 +2,6 @@ package provider
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -56,14 +55,7 @@ func (p *OpenRouterProvider) Name() string {
 }
 
 func (p *OpenRouterProvider) BuildUpstreamRequest(req normalize.NormalizedRequest) (*http.Request, error) {
-	openAIReq := openAIRequest{
-		Model:    req.Model,
-		Messages: req.Messages,
-		Stream:   req.Stream,
-		Tools:    req.Tools,
-	}
-
-	body, err := json.Marshal(openAIReq)
+	body, err := marshalOpenAIRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
@@ -96,3 +88,10 @@ func (p *OpenRouterProvider) ParseUpstreamResponse(resp *http.Response) (normali
 
 	return parseOpenAIResponse(body)
 }
+
+func (p *OpenRouterProvider) RewriteResponse(body []byte, edit normalize.ResponseEdit) ([]byte, error) {
+	if edit.IsEmpty() {
+		return body, nil
+	}
+	return rewriteOpenAIResponse(body, edit)
+}
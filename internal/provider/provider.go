@@ -1,13 +1,30 @@
 package provider
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/alereyleyva/agent-guard/internal/normalize"
 )
 
+// ErrUnsupportedContent is wrapped by BuildUpstreamRequest when the request
+// carries content the upstream API cannot represent.
+var ErrUnsupportedContent = errors.New("unsupported content")
+
+// ErrUnsupportedParameter is wrapped by BuildUpstreamRequest when a request
+// parameter has no equivalent in the upstream API.
+var ErrUnsupportedParameter = errors.New("unsupported parameter")
+
 type Provider interface {
 	Name() string
 	BuildUpstreamRequest(req normalize.NormalizedRequest) (*http.Request, error)
 	ParseUpstreamResponse(resp *http.Response) (normalize.NormalizedResponse, error)
+	RewriteResponse(body []byte, edit normalize.ResponseEdit) ([]byte, error)
+}
+
+// NativeResponder is implemented by providers whose upstream responses are
+// not in Chat Completions format. Flow renders their parsed responses as
+// chat.completion objects for the client.
+type NativeResponder interface {
+	NativeResponses() bool
 }
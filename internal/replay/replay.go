@@ -0,0 +1,330 @@
+// Package replay re-runs the policy evaluations recorded in an audit log
+// against a candidate policy and reports how the decisions would change.
+//
+// Audit events do not carry tool arguments or full request headers, so
+// evaluations are rebuilt from the model, caller, stream flag, timestamp
+// and the tool history recorded on each tool call decision, which includes
+// the request and session history. Logs written before decisions carried
+// their history fall back to the tool calls proposed earlier in the same
+// trace. Decisions made by argument constraints or by the detector guards
+// cannot be replayed and are counted as skipped.
+//
+// Without arguments, tool calls are replayed as declarations: the candidate's
+// argument constraints are not checked and rewrite rules report the rewrite
+// action without applying their patches.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+const (
+	ChangeNewlyDenied  = "newly_denied"
+	ChangeNewlyAllowed = "newly_allowed"
+	ChangeChanged      = "changed"
+	ChangeUnchanged    = "unchanged"
+)
+
+// Evaluation is one recorded request or tool call evaluation.
+type Evaluation struct {
+	TraceID        string
+	Time           time.Time
+	Model          string
+	RequestedModel string
+	Provider       string
+	Caller         string
+	Stream         bool
+	Tool           string
+	History        []string
+	Original       Outcome
+}
+
+type Outcome struct {
+	Action string
+	RuleID string
+}
+
+type Change struct {
+	Evaluation
+	Candidate Outcome
+	Kind      string
+}
+
+type Report struct {
+	Changes []Change
+	Skipped int
+}
+
+// ReadEvents decodes JSON-lines audit events. Lines that are not JSON
+// objects, such as startup messages on the same stream, are ignored.
+func ReadEvents(r io.Reader) ([]audit.Event, error) {
+	var events []audit.Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || text[0] != '{' {
+			continue
+		}
+		var event audit.Event
+		if err := json.Unmarshal(text, &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading events: %w", err)
+	}
+	return events, nil
+}
+
+// Rebuild groups events by trace and recovers the request evaluation and
+// each tool call evaluation with its recorded outcome. It also returns how
+// many recorded evaluations could not be rebuilt.
+func Rebuild(events []audit.Event) ([]Evaluation, int) {
+	traces := make(map[string][]audit.Event)
+	order := make([]string, 0)
+	for _, event := range events {
+		if _, ok := traces[event.TraceID]; !ok {
+			order = append(order, event.TraceID)
+		}
+		traces[event.TraceID] = append(traces[event.TraceID], event)
+	}
+
+	var evaluations []Evaluation
+	skipped := 0
+	for _, traceID := range order {
+		rebuilt, skip := rebuildTrace(traces[traceID])
+		evaluations = append(evaluations, rebuilt...)
+		skipped += skip
+	}
+	return evaluations, skipped
+}
+
+func rebuildTrace(events []audit.Event) ([]Evaluation, int) {
+	var request *Evaluation
+	var evaluations []Evaluation
+	var history []string
+	skipped := 0
+
+	for i, event := range events {
+		switch {
+		case event.EventType == audit.EventTypeLLMRequest:
+			t, _ := time.Parse(time.RFC3339, event.Timestamp)
+			request = &Evaluation{
+				TraceID:        event.TraceID,
+				Time:           t,
+				Model:          event.Model,
+				RequestedModel: event.RequestedModel,
+				Provider:       event.Provider,
+				Caller:         event.Caller,
+				Stream:         event.Stream,
+			}
+
+		case request != nil && request.Original.Action == "" && isRequestDecision(event):
+			request.Original = Outcome{Action: event.Decision, RuleID: event.RuleID}
+			if strings.HasPrefix(event.RuleID, "LIMIT_") {
+				skipped++
+				continue
+			}
+			evaluations = append(evaluations, *request)
+
+		case request != nil && event.EventType == audit.EventTypeToolProposal:
+			decision, ok := toolCallDecision(events[i+1:], event.ToolName)
+			switch {
+			case !ok:
+			case decision.ToolCallID != "" || decision.Constraint != "":
+				skipped++
+			default:
+				evaluation := *request
+				evaluation.Tool = event.ToolName
+				evaluation.History = append([]string{}, history...)
+				if decision.History != nil {
+					evaluation.History = decision.History
+				}
+				evaluation.Original = Outcome{Action: decision.Decision, RuleID: decision.RuleID}
+				evaluations = append(evaluations, evaluation)
+			}
+			history = append(history, event.ToolName)
+		}
+	}
+	return evaluations, skipped
+}
+
+func isRequestDecision(event audit.Event) bool {
+	return event.EventType == audit.EventTypePolicyDecision &&
+		!event.Shadow &&
+		event.ToolName == "" &&
+		event.ToolCallID == "" &&
+		event.MessageIndex == nil
+}
+
+// toolCallDecision finds the enforced decision for a proposed tool call:
+// the first non-shadow decision for the tool before the next proposal.
+func toolCallDecision(events []audit.Event, toolName string) (audit.Event, bool) {
+	for _, event := range events {
+		if event.EventType == audit.EventTypeToolProposal {
+			break
+		}
+		if event.EventType == audit.EventTypePolicyDecision && !event.Shadow && event.ToolName == toolName {
+			return event, true
+		}
+	}
+	return audit.Event{}, false
+}
+
+// Run evaluates every recorded evaluation against a candidate config.
+// Requested models are resolved through the candidate's aliases.
+func Run(cfg config.Config, evaluations []Evaluation) []Change {
+	engine := policy.NewEngine(cfg.Policy)
+	changes := make([]Change, 0, len(evaluations))
+	for _, evaluation := range evaluations {
+		in := policy.Input{
+			Model:          evaluation.Model,
+			RequestedModel: evaluation.RequestedModel,
+			Provider:       evaluation.Provider,
+			Tool:           evaluation.Tool,
+			Headers:        http.Header{},
+			Stream:         evaluation.Stream,
+			Time:           evaluation.Time,
+			History:        evaluation.History,
+		}
+		if in.RequestedModel != "" {
+			if resolved, ok := cfg.ModelAliases[in.RequestedModel]; ok {
+				in.Model = resolved
+			}
+		} else if resolved, ok := cfg.ModelAliases[in.Model]; ok {
+			in.Model, in.RequestedModel = resolved, in.Model
+		}
+		if evaluation.Caller != "" {
+			in.Headers.Set(engine.IdentityHeader(), evaluation.Caller)
+		}
+
+		// Tool calls go through EvaluateToolDeclaration because the arguments
+		// are not recorded: EvaluateToolCall would fail every rewrite patch
+		// and check constraints against empty arguments.
+		var decision policy.Decision
+		if in.Tool == "" {
+			decision = engine.EvaluateRequest(in)
+		} else {
+			decision = engine.EvaluateToolDeclaration(in)
+		}
+		candidate := Outcome{Action: decision.Action, RuleID: decision.RuleID}
+		changes = append(changes, Change{
+			Evaluation: evaluation,
+			Candidate:  candidate,
+			Kind:       classify(evaluation.Original, candidate),
+		})
+	}
+	return changes
+}
+
+func classify(original, candidate Outcome) string {
+	switch {
+	case original.Action == candidate.Action:
+		return ChangeUnchanged
+	case candidate.Action == policy.ActionDeny:
+		return ChangeNewlyDenied
+	case isAllowed(candidate.Action) && !isAllowed(original.Action):
+		return ChangeNewlyAllowed
+	default:
+		return ChangeChanged
+	}
+}
+
+func isAllowed(action string) bool {
+	return action == policy.ActionAllow || action == policy.ActionRewrite
+}
+
+// Counts returns the number of changes of each kind.
+func (r Report) Counts() map[string]int {
+	counts := make(map[string]int, 4)
+	for _, change := range r.Changes {
+		counts[change.Kind]++
+	}
+	return counts
+}
+
+// Write prints a summary followed by the changed decisions grouped by
+// candidate rule ID and tool. Unchanged decisions are grouped the same way
+// when verbose is set.
+func (r Report) Write(w io.Writer, verbose bool) {
+	counts := r.Counts()
+	fmt.Fprintf(w, "%d evaluations: %d newly denied, %d newly allowed, %d changed, %d unchanged, %d skipped\n",
+		len(r.Changes), counts[ChangeNewlyDenied], counts[ChangeNewlyAllowed], counts[ChangeChanged], counts[ChangeUnchanged], r.Skipped)
+
+	kinds := []string{ChangeNewlyDenied, ChangeNewlyAllowed, ChangeChanged}
+	if verbose {
+		kinds = append(kinds, ChangeUnchanged)
+	}
+	for _, kind := range kinds {
+		groups := groupChanges(r.Changes, kind)
+		if len(groups) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", kind)
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "  RULE\tTOOL\tWAS\tCOUNT")
+		for _, g := range groups {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\n", g.ruleID, g.tool, g.was, g.count)
+		}
+		_ = tw.Flush()
+	}
+}
+
+type changeGroup struct {
+	ruleID string
+	tool   string
+	was    string
+	count  int
+}
+
+func groupChanges(changes []Change, kind string) []changeGroup {
+	index := make(map[string]*changeGroup)
+	var groups []*changeGroup
+	for _, change := range changes {
+		if change.Kind != kind {
+			continue
+		}
+		tool := change.Tool
+		if tool == "" {
+			tool = "-"
+		}
+		was := change.Original.Action + "/" + change.Original.RuleID
+		key := strings.Join([]string{change.Candidate.RuleID, tool, was}, "\x00")
+		if g, ok := index[key]; ok {
+			g.count++
+			continue
+		}
+		g := &changeGroup{ruleID: change.Candidate.RuleID, tool: tool, was: was, count: 1}
+		index[key] = g
+		groups = append(groups, g)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].count != groups[j].count {
+			return groups[i].count > groups[j].count
+		}
+		return groups[i].ruleID < groups[j].ruleID
+	})
+	result := make([]changeGroup, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	return result
+}
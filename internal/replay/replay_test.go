@@ -0,0 +1,155 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alereyleyva/agent-guard/internal/audit"
+	"github.com/alereyleyva/agent-guard/internal/config"
+	"github.com/alereyleyva/agent-guard/internal/policy"
+)
+
+func recordedLog(t *testing.T) []byte {
+	t.Helper()
+	events := []audit.Event{
+		audit.NewEvent("t1", audit.EventTypeLLMRequest).WithModel("gpt-4o").WithCaller("support-bot"),
+		audit.NewEvent("t1", audit.EventTypePolicyDecision).WithModel("gpt-4o").WithDecision(policy.ActionAllow, "MODEL_ALLOW", ""),
+		audit.NewEvent("t1", audit.EventTypeLLMResponse).WithModel("gpt-4o"),
+		audit.NewEvent("t1", audit.EventTypeToolProposal).WithToolName("read_secrets"),
+		audit.NewEvent("t1", audit.EventTypePolicyDecision).WithToolName("read_secrets").WithDecision(policy.ActionAllow, "TOOL_ALLOW", ""),
+		audit.NewEvent("t1", audit.EventTypeToolProposal).WithToolName("http_post"),
+		audit.NewEvent("t1", audit.EventTypePolicyDecision).WithToolName("http_post").WithDecision(policy.ActionAllow, "TOOL_ALLOW", ""),
+		audit.NewEvent("t1", audit.EventTypeToolProposal).WithToolName("read_file"),
+		audit.NewEvent("t1", audit.EventTypePolicyDecision).WithToolName("read_file").WithConstraint("workspace-only").WithDecision(policy.ActionDeny, "TOOL_CONSTRAINT", ""),
+
+		audit.NewEvent("t2", audit.EventTypeLLMRequest).WithModel("gpt-3.5-turbo"),
+		audit.NewEvent("t2", audit.EventTypePolicyDecision).WithModel("gpt-3.5-turbo").WithDecision(policy.ActionDeny, "MODEL_DEFAULT_DENY", ""),
+
+		audit.NewEvent("t3", audit.EventTypeLLMRequest).WithModel("gpt-4o"),
+		audit.NewEvent("t3", audit.EventTypePolicyDecision).WithModel("gpt-4o").WithDecision(policy.ActionAllow, "MODEL_ALLOW", ""),
+		audit.NewEvent("t3", audit.EventTypeToolProposal).WithToolName("deploy_prod"),
+		audit.NewEvent("t3", audit.EventTypePolicyDecision).WithToolName("deploy_prod").WithShadow(true).WithDecision(policy.ActionDeny, "freeze", ""),
+		audit.NewEvent("t3", audit.EventTypePolicyDecision).WithToolName("deploy_prod").WithDecision(policy.ActionAllow, "TOOL_ALLOW", ""),
+
+		// read_secrets ran earlier in the session, so it is only in the
+		// history recorded on the decision.
+		audit.NewEvent("t4", audit.EventTypeLLMRequest).WithModel("gpt-4o"),
+		audit.NewEvent("t4", audit.EventTypePolicyDecision).WithModel("gpt-4o").WithDecision(policy.ActionAllow, "MODEL_ALLOW", ""),
+		audit.NewEvent("t4", audit.EventTypeToolProposal).WithToolName("http_post"),
+		audit.NewEvent("t4", audit.EventTypePolicyDecision).WithToolName("http_post").WithHistory([]string{"read_secrets"}).WithDecision(policy.ActionAllow, "TOOL_ALLOW", ""),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("AgentGuard starting on 127.0.0.1:8080\n")
+	logger := audit.NewJSONLogger(&buf)
+	for _, event := range events {
+		logger.Emit(event)
+	}
+	return buf.Bytes()
+}
+
+func TestRebuild(t *testing.T) {
+	events, err := ReadEvents(bytes.NewReader(recordedLog(t)))
+	if err != nil {
+		t.Fatalf("ReadEvents() error = %v", err)
+	}
+
+	evaluations, skipped := Rebuild(events)
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1 (constraint decision)", skipped)
+	}
+
+	var got []string
+	for _, e := range evaluations {
+		got = append(got, e.TraceID+":"+e.Tool+":"+e.Original.Action+":"+strings.Join(e.History, ","))
+	}
+	want := []string{
+		"t1::allow:",
+		"t1:read_secrets:allow:",
+		"t1:http_post:allow:read_secrets",
+		"t2::deny:",
+		"t3::allow:",
+		"t3:deploy_prod:allow:",
+		"t4::allow:",
+		"t4:http_post:allow:read_secrets",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("evaluations = %v, want %v", got, want)
+	}
+	if evaluations[1].Caller != "support-bot" {
+		t.Errorf("Caller = %q, want support-bot", evaluations[1].Caller)
+	}
+}
+
+func TestRunAndReport(t *testing.T) {
+	events, err := ReadEvents(bytes.NewReader(recordedLog(t)))
+	if err != nil {
+		t.Fatalf("ReadEvents() error = %v", err)
+	}
+	evaluations, skipped := Rebuild(events)
+
+	candidate := config.Config{Policy: config.PolicyConfig{
+		Rules: []config.PolicyRule{
+			{ID: "no-exfiltration", Action: config.RuleActionDeny, Match: config.RuleMatch{Tools: []string{"http_post"}, CalledBefore: []string{"read_secrets"}}},
+			{ID: "approve-deploys", Action: config.RuleActionRequireApproval, Match: config.RuleMatch{Tools: []string{"deploy_*"}}},
+		},
+		Models: config.ModelPolicy{Allow: []string{"gpt-4o", "gpt-3.5-turbo"}},
+		Tools:  config.ToolPolicy{Allow: []string{"*"}},
+	}}
+	report := Report{Changes: Run(candidate, evaluations), Skipped: skipped}
+
+	counts := report.Counts()
+	if counts[ChangeNewlyDenied] != 2 || counts[ChangeNewlyAllowed] != 1 || counts[ChangeChanged] != 1 || counts[ChangeUnchanged] != 4 {
+		t.Errorf("Counts() = %v", counts)
+	}
+
+	var out bytes.Buffer
+	report.Write(&out, false)
+	for _, want := range []string{
+		"8 evaluations: 2 newly denied, 1 newly allowed, 1 changed, 4 unchanged, 1 skipped",
+		"newly_denied:",
+		"no-exfiltration  http_post  allow/TOOL_ALLOW  2",
+		"newly_allowed:",
+		"MODEL_ALLOW  -     deny/MODEL_DEFAULT_DENY",
+		"changed:",
+		"approve-deploys  deploy_prod",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "unchanged:") {
+		t.Errorf("report lists unchanged decisions without verbose:\n%s", out.String())
+	}
+}
+
+func TestRun_ExampleConfigOffline(t *testing.T) {
+	for _, name := range []string{"OPENAI_API_KEY", "OPENROUTER_API_KEY", "AGENTGUARD_ADMIN_TOKEN", "AGENTGUARD_PII_KEY"} {
+		t.Setenv(name, "")
+	}
+	cfg, err := config.LoadPolicy(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+
+	events, err := ReadEvents(bytes.NewReader(recordedLog(t)))
+	if err != nil {
+		t.Fatalf("ReadEvents() error = %v", err)
+	}
+	evaluations, _ := Rebuild(events)
+	if changes := Run(*cfg, evaluations); len(changes) != len(evaluations) {
+		t.Errorf("Run() returned %d changes, want %d", len(changes), len(evaluations))
+	}
+}
+
+func TestReadEvents_InvalidLine(t *testing.T) {
+	data, _ := json.Marshal(audit.NewEvent("t1", audit.EventTypeLLMRequest))
+	input := string(data) + "\n{not json\n"
+
+	if _, err := ReadEvents(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ReadEvents() error = %v, want line 2 error", err)
+	}
+}